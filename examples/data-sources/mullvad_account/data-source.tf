@@ -1,3 +1,7 @@
 // Retrieve information about the configured account
 data "mullvad_account" "example" {
 }
+
+output "mullvad_peers" {
+  value = [for peer in data.mullvad_account.example.wireguard_peers : peer.public_key]
+}
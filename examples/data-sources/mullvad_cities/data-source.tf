@@ -0,0 +1,9 @@
+// List the cities in Sweden, e.g. to validate a configured city code
+
+data "mullvad_cities" "se" {
+  country_code = "se"
+}
+
+locals {
+  se_city_codes = [for c in data.mullvad_cities.se.cities : c.city_code]
+}
@@ -8,3 +8,10 @@ locals {
   country_code = data.mullvad_city.london.country_code
   city_code    = data.mullvad_city.london.city_code
 }
+
+// Get the name of the city for a location code
+
+data "mullvad_city" "gb_mnc" {
+  country_code = "gb"
+  city_code    = "mnc"
+}
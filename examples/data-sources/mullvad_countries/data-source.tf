@@ -0,0 +1,11 @@
+// List every country with WireGuard relays
+
+data "mullvad_countries" "all" {
+}
+
+locals {
+  wireguard_countries = {
+    for c in data.mullvad_countries.all.countries : c.code => c.name
+    if c.wireguard_relays > 0
+  }
+}
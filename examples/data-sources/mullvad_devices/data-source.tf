@@ -0,0 +1,8 @@
+// List the names of the devices on the account
+
+data "mullvad_devices" "all" {
+}
+
+locals {
+  device_names = [for d in data.mullvad_devices.all.devices : d.name]
+}
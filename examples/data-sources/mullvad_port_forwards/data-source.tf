@@ -0,0 +1,8 @@
+// Look up the ports forwarded for a peer, without managing them
+data "mullvad_port_forwards" "example" {
+  public_key = "dBmRyzNvzqDXsKmRfGkv9ws/ZpTyZGsl6ocTLnFvn10="
+}
+
+output "ports" {
+  value = [for port_forward in data.mullvad_port_forwards.example.port_forwards : port_forward.port]
+}
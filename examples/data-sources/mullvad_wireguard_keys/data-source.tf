@@ -0,0 +1,6 @@
+// List keys registered over 90 days ago, with ports forwarded in Sweden
+data "mullvad_wireguard_keys" "example" {
+  assignment      = "assigned"
+  country_code    = "se"
+  older_than_days = 90
+}
@@ -0,0 +1,7 @@
+terraform {
+  required_providers {
+    mullvad = {
+      source = "OJFord/mullvad"
+    }
+  }
+}
@@ -1,2 +1,2 @@
-# import using the (secret) account ID
-terraform import mullvad_account.example 0123456789
+# import using the (secret) account number, after which the ID is its UUID
+terraform import mullvad_account.example 1234567890123456
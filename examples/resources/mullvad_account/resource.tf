@@ -1,3 +1,18 @@
 // Provision a new Mullvad account
 resource "mullvad_account" "example" {
 }
+
+// Or adopt an existing one
+variable "mullvad_account_number" {
+  type      = string
+  sensitive = true
+}
+
+resource "mullvad_account" "existing" {
+  account_number = var.mullvad_account_number
+}
+
+// Delete a short-lived (e.g. test) account when destroyed
+resource "mullvad_account" "ephemeral" {
+  delete_on_destroy = true
+}
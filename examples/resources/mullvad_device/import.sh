@@ -0,0 +1,2 @@
+# import using the device's ID
+terraform import mullvad_device.laptop 5ee6b1a4-6bde-4b6e-a5a2-a5c3a06f4a5e
@@ -0,0 +1,6 @@
+resource "wireguard_asymmetric_key" "laptop" {
+}
+
+resource "mullvad_device" "laptop" {
+  public_key = wireguard_asymmetric_key.laptop.public_key
+}
@@ -0,0 +1,2 @@
+# import using <country_code>-<city_code>:<port>
+terraform import mullvad_port_forward.wireguard gb-lon:54321
@@ -0,0 +1,13 @@
+variable "voucher_code" {
+  type      = string
+  sensitive = true
+}
+
+resource "mullvad_account" "example" {
+}
+
+// Fund the account with a voucher
+resource "mullvad_voucher_redemption" "example" {
+  account_number = mullvad_account.example.account_number
+  voucher_code   = var.voucher_code
+}
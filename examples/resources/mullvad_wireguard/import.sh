@@ -0,0 +1,2 @@
+# import using the peer's public key
+terraform import mullvad_wireguard.my_peer 'IhB8Ud+Ar6dDJBp8pzEgsnn6bDPZcl4zy+zpyoc7/h4='
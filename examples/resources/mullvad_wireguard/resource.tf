@@ -3,4 +3,7 @@ resource "wireguard_asymmetric_key" "my_peer" {
 
 resource "mullvad_wireguard" "my_peer" {
   public_key = wireguard_asymmetric_key.my_peer.public_key
+
+  // Optionally, expose in `expires_at` when the key is due to be rotated
+  rotation_days = 90
 }
@@ -0,0 +1,9 @@
+// Generate a new key every 90 days, which replaces the old in place,
+// keeping its addresses and forwarded ports
+resource "mullvad_wireguard_keypair" "my_peer" {
+  rotation_days = 90
+}
+
+resource "mullvad_wireguard" "my_peer" {
+  public_key = mullvad_wireguard_keypair.my_peer.public_key
+}
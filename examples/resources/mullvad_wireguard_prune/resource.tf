@@ -0,0 +1,14 @@
+resource "mullvad_wireguard" "my_peer" {
+  public_key = wireguard_asymmetric_key.my_peer.public_key
+}
+
+// Revoke keys of decommissioned machines, registered over 30 days ago
+resource "mullvad_wireguard_prune" "stale" {
+  keep = [mullvad_wireguard.my_peer.public_key]
+
+  older_than_days        = 30
+  exclude_app_registered = true
+}
+
+resource "wireguard_asymmetric_key" "my_peer" {
+}
@@ -6,8 +6,11 @@ import (
 	"net/http"
 )
 
-func (c *Client) CreateAccount() (*Account, error) {
-	resp, err := c.R().SetResult(LoginResponse{}).Post("www/accounts/")
+func (c *Client) CreateAccount() (_ *Account, err error) {
+	defer func() { c.audit(AuditRecord{Operation: "create_account"}, err) }()
+
+	// Not logged, since the response contains the new account's number.
+	resp, err := c.R().SetDebug(false).SetResult(LoginResponse{}).Post("www/accounts/")
 	if err != nil {
 		return nil, err
 	}
@@ -18,22 +21,54 @@ func (c *Client) CreateAccount() (*Account, error) {
 	}
 
 	login := resp.Result().(*LoginResponse)
-	c.AuthToken = login.AuthToken
+	c.setLogin(login)
 
 	return &login.Account, nil
 }
 
 func (c *Client) GetAccount() (*Account, error) {
-	resp, err := c.R().SetResult(MeResponse{}).Get("www/me/")
+	result, err := c.coalescedGet("www/me/", MeResponse{}, "Failed to read account info")
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode() != http.StatusOK {
-		log.Printf("[ERROR] %s", resp.Status())
-		return nil, errors.New("Failed to read account info")
+	acc := result.(*MeResponse).Account
+	return &acc, nil
+}
+
+// GetAccountID gets the logged in account's (non-secret) UUID.
+func (c *Client) GetAccountID() (string, error) {
+	result, err := c.coalescedGet("accounts/v1/accounts/me", AccountResponse{}, "Failed to read account ID")
+	if err != nil {
+		return "", err
 	}
 
-	acc := resp.Result().(*MeResponse).Account
-	return &acc, nil
+	return result.(*AccountResponse).ID, nil
+}
+
+// DeleteAccount irreversibly deletes the logged in account, including any
+// remaining paid time.
+func (c *Client) DeleteAccount() (err error) {
+	defer func() { c.audit(AuditRecord{Operation: "delete_account"}, err) }()
+
+	c.accessTokenMu.Lock()
+	account_number := c.accountNumber
+	c.accessTokenMu.Unlock()
+
+	// Not logged, since the header contains the account number.
+	resp, err := c.R().SetDebug(false).SetHeader("Mullvad-Account-Number", account_number).Delete("accounts/v1/accounts/me")
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != http.StatusNoContent {
+		log.Printf("[ERROR] %s: %s", resp.Status(), resp.Body())
+		return errors.New("Failed to delete account")
+	}
+
+	c.accessTokenMu.Lock()
+	c.accessToken = nil
+	c.accessTokenMu.Unlock()
+
+	return nil
 }
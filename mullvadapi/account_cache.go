@@ -0,0 +1,119 @@
+package mullvadapi
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/sync/singleflight"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// How long a list of account data (keys, ports) is reused, if nothing has been
+// changed through the client in the meantime.
+const AccountCacheTTL = 30 * time.Second
+
+// Maximum number of requests to the API in flight at once.
+const MaxConcurrentRequests = 4
+
+type accountCacheEntry struct {
+	result     interface{}
+	fetched    time.Time
+	generation uint64
+}
+
+// accountCache coalesces reads of account data, such that refreshing many
+// `mullvad_wireguard` or `mullvad_port_forward` resources lists the keys or
+// ports once, rather than once per resource. Any mutation invalidates it.
+type accountCache struct {
+	mu         sync.Mutex
+	entries    map[string]accountCacheEntry
+	generation uint64
+	group      singleflight.Group
+}
+
+func newAccountCache() *accountCache {
+	return &accountCache{
+		entries: make(map[string]accountCacheEntry),
+	}
+}
+
+func (ac *accountCache) get(path string) (interface{}, uint64, bool) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	entry, exists := ac.entries[path]
+	if !exists || entry.generation != ac.generation || time.Since(entry.fetched) >= AccountCacheTTL {
+		return nil, ac.generation, false
+	}
+
+	return entry.result, ac.generation, true
+}
+
+func (ac *accountCache) put(path string, generation uint64, result interface{}) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if generation != ac.generation {
+		// Invalidated while in flight, may not reflect the change.
+		return
+	}
+
+	ac.entries[path] = accountCacheEntry{result, time.Now(), generation}
+}
+
+func (ac *accountCache) invalidate() {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	ac.generation++
+	ac.entries = make(map[string]accountCacheEntry)
+}
+
+// The returned result is shared between callers, and must not be modified.
+func (c *Client) coalescedGet(path string, result interface{}, failure string) (interface{}, error) {
+	cached, generation, exists := c.accountCache.get(path)
+	if exists {
+		log.Printf("[DEBUG] Using recently read %s", path)
+		return cached, nil
+	}
+
+	shared, err, _ := c.accountCache.group.Do(fmt.Sprintf("%d:%s", generation, path), func() (interface{}, error) {
+		resp, err := c.R().SetResult(result).Get(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode() != http.StatusOK {
+			log.Printf("[ERROR] %s: %s", resp.Status(), resp.Body())
+			return nil, errors.New(failure)
+		}
+
+		c.accountCache.put(path, generation, resp.Result())
+		return resp.Result(), nil
+	})
+
+	return shared, err
+}
+
+// limitingTransport bounds the number of concurrent requests, and invalidates
+// the account cache once any (possibly) mutating request completes.
+type limitingTransport struct {
+	base         http.RoundTripper
+	slots        chan struct{}
+	accountCache *accountCache
+}
+
+func (t *limitingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.slots <- struct{}{}
+	defer func() { <-t.slots }()
+
+	resp, err := t.base.RoundTrip(req)
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead && req.URL.Path != "/auth/v1/token" {
+		t.accountCache.invalidate()
+	}
+
+	return resp, err
+}
@@ -0,0 +1,156 @@
+package mullvadapi
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// A logged in client, of an API listing keys (blocking until release, if not
+// nil) and registering them.
+func newKeysTestClient(t *testing.T, lists *int32, release chan struct{}) *Client {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/www/wg-pubkeys/list/":
+			atomic.AddInt32(lists, 1)
+			if release != nil {
+				<-release
+			}
+			w.Write([]byte(`{"keys": [], "max_ports": 5}`))
+
+		case "/www/wg-pubkeys/add/":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	client.setLogin(&LoginResponse{Account{Token: "1234567890123456"}, "token"})
+	return client
+}
+
+func TestListWireGuardKeysCoalescesConcurrentReads(t *testing.T) {
+	var lists int32
+	release := make(chan struct{})
+	client := newKeysTestClient(t, &lists, release)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.ListWireGuardKeys(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if _, err := client.ListWireGuardKeys(); err != nil {
+		t.Fatal(err)
+	}
+
+	if lists != 1 {
+		t.Errorf("Expected 1 list request, got %d", lists)
+	}
+}
+
+func TestMutationInvalidatesAccountCache(t *testing.T) {
+	var lists int32
+	client := newKeysTestClient(t, &lists, nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.ListWireGuardKeys(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := client.AddWireGuardKey("key"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.ListWireGuardKeys(); err != nil {
+		t.Fatal(err)
+	}
+
+	if lists != 2 {
+		t.Errorf("Expected 2 list requests, got %d", lists)
+	}
+}
+
+func TestReadInFlightDuringMutationIsNotCached(t *testing.T) {
+	var lists int32
+	release := make(chan struct{})
+	client := newKeysTestClient(t, &lists, release)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := client.ListWireGuardKeys(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	for atomic.LoadInt32(&lists) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := client.AddWireGuardKey("key"); err != nil {
+		t.Fatal(err)
+	}
+
+	close(release)
+	<-done
+
+	if _, err := client.ListWireGuardKeys(); err != nil {
+		t.Fatal(err)
+	}
+
+	if lists != 2 {
+		t.Errorf("Expected the read in flight not to be reused, got %d list requests", lists)
+	}
+}
+
+func TestConcurrentRequestsAreLimited(t *testing.T) {
+	var in_flight, max_in_flight int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&in_flight, 1)
+		defer atomic.AddInt32(&in_flight, -1)
+
+		for {
+			max := atomic.LoadInt32(&max_in_flight)
+			if n <= max || atomic.CompareAndSwapInt32(&max_in_flight, max, n) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4*MaxConcurrentRequests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Public, so that no login is required.
+			if _, err := client.R().Get(fmt.Sprintf("www/relays/%d/", i)); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if max_in_flight != MaxConcurrentRequests {
+		t.Errorf("Expected at most (and up to) %d requests in flight, got %d", MaxConcurrentRequests, max_in_flight)
+	}
+}
@@ -0,0 +1,64 @@
+package mullvadapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAccountNumberIsNotLogged(t *testing.T) {
+	account := `{"token": "1234567890123456", "expiry_unix": 1893456000}`
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/www/accounts/":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"auth_token": "token", "account": ` + account + `}`))
+		case "/www/accounts/1234567890123456/":
+			w.Write([]byte(`{"auth_token": "token", "account": ` + account + `}`))
+		case "/www/me/":
+			w.Write([]byte(`{"account": ` + account + `}`))
+		case "/auth/v1/token":
+			w.Write([]byte(`{"access_token": "token", "expiry": "2030-01-01T00:00:00Z"}`))
+		case "/accounts/v1/devices":
+			w.Write([]byte(`[]`))
+		case "/accounts/v1/accounts/me":
+			if r.Header.Get("Mullvad-Account-Number") != "1234567890123456" {
+				t.Errorf("Expected the account number header, got %q", r.Header.Get("Mullvad-Account-Number"))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	logs := captureLogs(t, client)
+
+	if _, err := client.CreateAccount(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Login("1234567890123456"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.GetAccount(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.ListDevices(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.DeleteAccount(); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(logs.String(), "1234567890123456") {
+		t.Errorf("Expected the account number not to be logged, got:\n%s", logs)
+	}
+
+	if !strings.Contains(logs.String(), "www/me/") {
+		t.Errorf("Expected other requests still to be logged, got:\n%s", logs)
+	}
+}
@@ -0,0 +1,150 @@
+package mullvadapi
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditRecord describes a (possibly) mutating request made by the client. It
+// never contains the account number, only its HMAC (keyed by the client's
+// audit key, which is not recorded) - a plain hash of so few digits would be
+// easily reversed.
+type AuditRecord struct {
+	Time      time.Time `json:"time"`
+	RunID     string    `json:"run_id"`
+	Operation string    `json:"operation"`
+	Account   string    `json:"account_hmac_sha256,omitempty"`
+	PublicKey string    `json:"public_key,omitempty"`
+	OldKey    string    `json:"old_public_key,omitempty"`
+	Device    string    `json:"device,omitempty"`
+	Location  string    `json:"location,omitempty"`
+	Port      int       `json:"port,omitempty"`
+	Outcome   string    `json:"outcome"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// AuditSink receives an AuditRecord for each mutation through the client.
+type AuditSink interface {
+	Record(record AuditRecord) error
+}
+
+// FileAuditSink appends records as JSON Lines to a file.
+type FileAuditSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileAuditSink(path string) *FileAuditSink {
+	return &FileAuditSink{path: path}
+}
+
+func (s *FileAuditSink) Record(record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	// A single write to a file opened for appending, such that lines from
+	// concurrent runs sharing the file aren't interleaved.
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func (c *Client) hashAccount(account_number string) string {
+	if account_number == "" {
+		return ""
+	}
+
+	c.auditMu.Lock()
+	mac := hmac.New(sha256.New, c.auditKey)
+	c.auditMu.Unlock()
+
+	mac.Write([]byte(account_number))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
+
+func newRunID() string {
+	return randomHex(8)
+}
+
+// NewAuditKey generates a random key with which to HMAC account numbers.
+func NewAuditKey() string {
+	return randomHex(32)
+}
+
+// SetAuditKey sets the key with which account numbers are HMAC'd in audit
+// records, such that they can be correlated across runs by whoever holds it.
+// By default it's random, so records of different clients can't be.
+func (c *Client) SetAuditKey(key string) {
+	c.auditMu.Lock()
+	defer c.auditMu.Unlock()
+
+	c.auditKey = []byte(key)
+}
+
+// AddAuditSink records each subsequent mutation through the client to sink.
+func (c *Client) AddAuditSink(sink AuditSink) {
+	c.auditMu.Lock()
+	defer c.auditMu.Unlock()
+
+	c.auditSinks = append(c.auditSinks, sink)
+}
+
+func (c *Client) audit(record AuditRecord, err error) {
+	c.auditMu.Lock()
+	sinks := c.auditSinks
+	c.auditMu.Unlock()
+
+	if len(sinks) == 0 {
+		return
+	}
+
+	record.Time = time.Now().UTC()
+	record.RunID = c.RunID
+	if record.Account == "" {
+		c.accessTokenMu.Lock()
+		account_number := c.accountNumber
+		c.accessTokenMu.Unlock()
+
+		record.Account = c.hashAccount(account_number)
+	}
+	record.Outcome = "success"
+	if err != nil {
+		record.Outcome = "failure"
+		record.Error = err.Error()
+	}
+
+	for _, sink := range sinks {
+		if err := sink.Record(record); err != nil {
+			log.Printf("[ERROR] Failed to record %s to audit log: %s", record.Operation, err)
+		}
+	}
+}
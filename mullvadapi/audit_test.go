@@ -0,0 +1,61 @@
+package mullvadapi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditRecordsHMACOfAccount(t *testing.T) {
+	client, err := GetClient("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	client.SetAuditKey("secret")
+	client.AddAuditSink(NewFileAuditSink(path))
+	client.setLogin(&LoginResponse{Account{Token: "1234567890123456"}, "token"})
+
+	client.audit(AuditRecord{Operation: "add_wireguard_key", PublicKey: "key"}, nil)
+	client.audit(AuditRecord{Operation: "revoke_wireguard_key", PublicKey: "key"}, errors.New("Failed"))
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(raw), "1234567890123456") {
+		t.Error("Expected the account number not to be recorded")
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1234567890123456"))
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(lines))
+	}
+
+	for i, outcome := range []string{"success", "failure"} {
+		var record AuditRecord
+		if err := json.Unmarshal([]byte(lines[i]), &record); err != nil {
+			t.Fatal(err)
+		}
+
+		if record.Account != expected {
+			t.Errorf("Expected account %s, got %s", expected, record.Account)
+		}
+
+		if record.Outcome != outcome || record.RunID != client.RunID {
+			t.Errorf("Unexpected record %+v", record)
+		}
+	}
+}
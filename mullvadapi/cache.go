@@ -0,0 +1,232 @@
+package mullvadapi
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/sync/singleflight"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How long a cached response is used without revalidating it with the API.
+const DefaultCacheTTL = 5 * time.Minute
+
+// Responses captured at build time (by `make snapshot`), of last resort in
+// offline mode. Only the README is committed, so other builds have none.
+//
+//go:embed snapshot
+var snapshotFS embed.FS
+
+type cacheEntry struct {
+	Body    json.RawMessage `json:"body"`
+	ETag    string          `json:"etag"`
+	Fetched time.Time       `json:"fetched"`
+}
+
+// StaleResponseError is returned along with a result when, in offline mode,
+// the API could not be reached and a cached or built-in response was used.
+type StaleResponseError struct {
+	Err     error
+	Fetched time.Time
+	Source  string
+}
+
+func (e *StaleResponseError) Error() string {
+	if e.Fetched.IsZero() {
+		return fmt.Sprintf("Using %s response of unknown age, since the Mullvad API could not be reached: %s", e.Source, e.Err)
+	}
+
+	return fmt.Sprintf(
+		"Using %s response fetched at %s (%s ago), since the Mullvad API could not be reached: %s",
+		e.Source,
+		e.Fetched.Format(time.RFC3339),
+		time.Since(e.Fetched).Round(time.Minute),
+		e.Err,
+	)
+}
+
+func (e *StaleResponseError) Unwrap() error {
+	return e.Err
+}
+
+// responseCache holds the bodies of account-independent GET responses, such
+// that e.g. many `mullvad_relay` data sources in one configuration only fetch
+// the relay list once.
+type responseCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	dir     string
+	entries map[string]*cacheEntry
+	group   singleflight.Group
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{
+		ttl:     ttl,
+		entries: make(map[string]*cacheEntry),
+	}
+}
+
+func cacheFileName(path string) string {
+	return strings.ReplaceAll(strings.Trim(path, "/"), "/", "_") + ".json"
+}
+
+func (rc *responseCache) get(path string) (cacheEntry, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if entry, exists := rc.entries[path]; exists {
+		return *entry, true
+	}
+
+	if rc.dir == "" {
+		return cacheEntry{}, false
+	}
+
+	raw, err := os.ReadFile(filepath.Join(rc.dir, cacheFileName(path)))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("[WARN] Failed to read cached response for %s: %s", path, err)
+		}
+		return cacheEntry{}, false
+	}
+
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(raw, entry); err != nil {
+		log.Printf("[WARN] Ignoring corrupt cached response for %s: %s", path, err)
+		return cacheEntry{}, false
+	}
+
+	rc.entries[path] = entry
+	return *entry, true
+}
+
+func (rc *responseCache) put(path string, entry cacheEntry) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.entries[path] = &entry
+
+	if rc.dir != "" {
+		if err := writeCacheFile(filepath.Join(rc.dir, cacheFileName(path)), &entry); err != nil {
+			log.Printf("[WARN] Failed to persist cached response for %s: %s", path, err)
+		}
+	}
+}
+
+// Written via a temporary file & rename, so that concurrent runs sharing a
+// `cache_dir` never see a partial file.
+func writeCacheFile(name string, entry *cacheEntry) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-"+filepath.Base(name))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+func snapshot(path string) (cacheEntry, bool) {
+	body, err := snapshotFS.ReadFile("snapshot/" + cacheFileName(path))
+	if err != nil {
+		return cacheEntry{}, false
+	}
+
+	entry := cacheEntry{Body: body}
+	if fetched, err := snapshotFS.ReadFile("snapshot/fetched"); err == nil {
+		entry.Fetched, _ = time.Parse(time.RFC3339, strings.TrimSpace(string(fetched)))
+	}
+
+	return entry, true
+}
+
+// SetCacheDir persists cached responses in dir, such that they survive between
+// runs (and may be used in offline mode).
+func (c *Client) SetCacheDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+	c.cache.dir = dir
+	return nil
+}
+
+func (c *Client) cachedGet(path string, failure string) ([]byte, error) {
+	if entry, exists := c.cache.get(path); exists && time.Since(entry.Fetched) < c.cache.ttl {
+		log.Printf("[DEBUG] Using cached response for %s", path)
+		return entry.Body, nil
+	}
+
+	body, err, _ := c.cache.group.Do(path, func() (interface{}, error) {
+		req := c.R()
+
+		cached, exists := c.cache.get(path)
+		if exists && cached.ETag != "" {
+			req.SetHeader("If-None-Match", cached.ETag)
+		}
+
+		resp, err := req.Get(path)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case resp.StatusCode() == http.StatusNotModified && exists:
+			log.Printf("[DEBUG] Cached response for %s not modified", path)
+			cached.Fetched = time.Now()
+			c.cache.put(path, cached)
+			return []byte(cached.Body), nil
+
+		case resp.StatusCode() == http.StatusOK:
+			c.cache.put(path, cacheEntry{
+				Body:    resp.Body(),
+				ETag:    resp.Header().Get("ETag"),
+				Fetched: time.Now(),
+			})
+			return resp.Body(), nil
+		}
+
+		log.Printf("[ERROR] %s: %s", resp.Status(), resp.Body())
+		return nil, errors.New(failure)
+	})
+	if err == nil {
+		return body.([]byte), nil
+	}
+
+	if !c.Offline {
+		return nil, err
+	}
+
+	if entry, exists := c.cache.get(path); exists {
+		return entry.Body, &StaleResponseError{err, entry.Fetched, "cached"}
+	}
+
+	if entry, exists := snapshot(path); exists {
+		return entry.Body, &StaleResponseError{err, entry.Fetched, "built-in"}
+	}
+
+	return nil, err
+}
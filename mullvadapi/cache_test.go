@@ -0,0 +1,132 @@
+package mullvadapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := GetClient("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.SetDebug(false)
+	client.SetHostURL(server.URL)
+	return client
+}
+
+func TestCachedGetCoalescesConcurrentRequests(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		w.Write([]byte(`[]`))
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.cachedGet("www/relays/all/", "Failed"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if _, err := client.cachedGet("www/relays/all/", "Failed"); err != nil {
+		t.Fatal(err)
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+}
+
+func TestCachedGetRevalidatesWithETag(t *testing.T) {
+	var requests, not_modified int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&not_modified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`["relay"]`))
+	}))
+	client.cache.ttl = 0
+
+	for i := 0; i < 2; i++ {
+		body, err := client.cachedGet("www/relays/all/", "Failed")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(body) != `["relay"]` {
+			t.Errorf("Unexpected body %s", body)
+		}
+	}
+
+	if requests != 2 || not_modified != 1 {
+		t.Errorf("Expected 2 requests, 1 not modified; got %d, %d", requests, not_modified)
+	}
+}
+
+func TestCachedGetOfflineFallsBackToCache(t *testing.T) {
+	var unavailable atomic.Bool
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if unavailable.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(`["relay"]`))
+	}))
+	client.cache.ttl = 0
+	client.Offline = true
+
+	if _, err := client.cachedGet("www/relays/all/", "Failed"); err != nil {
+		t.Fatal(err)
+	}
+
+	unavailable.Store(true)
+	body, err := client.cachedGet("www/relays/all/", "Failed")
+
+	var stale *StaleResponseError
+	if !errors.As(err, &stale) || stale.Source != "cached" {
+		t.Fatalf("Expected a stale cached response, got %v", err)
+	}
+
+	if string(body) != `["relay"]` {
+		t.Errorf("Unexpected body %s", body)
+	}
+
+	client.Offline = false
+	if _, err := client.cachedGet("www/relays/all/", "Failed"); err == nil || errors.As(err, &stale) {
+		t.Errorf("Expected failure when not offline, got %v", err)
+	}
+}
+
+func TestStaleResponseErrorOfUnknownAge(t *testing.T) {
+	err := &StaleResponseError{errors.New("unreachable"), time.Time{}, "built-in"}
+	expected := "Using built-in response of unknown age, since the Mullvad API could not be reached: unreachable"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
@@ -1,21 +1,20 @@
 package mullvadapi
 
 import (
-	"errors"
-	"log"
-	"net/http"
+	"encoding/json"
 )
 
 func (c *Client) ListCities() (*[]CityResponse, error) {
-	resp, err := c.R().SetResult([]CityResponse{}).Get("www/cities/")
-	if err != nil {
+	body, err := c.cachedGet("www/cities/", "Failed to read available cities")
+	if body == nil {
 		return nil, err
 	}
 
-	if resp.StatusCode() != http.StatusOK {
-		log.Printf("[ERROR] %s: %s", resp.Status(), resp.Body())
-		return nil, errors.New("Failed to read available cities")
+	cities := []CityResponse{}
+	if err := json.Unmarshal(body, &cities); err != nil {
+		return nil, err
 	}
 
-	return resp.Result().(*[]CityResponse), nil
+	// Possibly a StaleResponseError
+	return &cities, err
 }
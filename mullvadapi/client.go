@@ -6,29 +6,77 @@ import (
 	"github.com/go-resty/resty/v2"
 	"log"
 	"net/http"
+	"net/url"
 	"strings"
-	"time"
+	"sync"
+	"sync/atomic"
 )
 
+var ErrReadOnly = errors.New("Refusing to modify Mullvad account with read-only client")
+
 type Client struct {
 	resty.Client
-	AuthToken string
+	// Offline falls back to cached (or built-in) responses where possible,
+	// if the API cannot be reached.
+	Offline bool
+	// AllowRevokeAppKeys permits revoking keys registered by the Mullvad app,
+	// which may be in use.
+	AllowRevokeAppKeys bool
+	// ReadOnly refuses any request that may modify the account (or create one).
+	ReadOnly bool
+	// RunID identifies the client's requests in audit records.
+	RunID        string
+	cache        *responseCache
+	accountCache *accountCache
+
+	auditMu    sync.Mutex
+	auditKey   []byte
+	auditSinks []AuditSink
+
+	// Login may happen (e.g. by a `mullvad_account`) concurrently with, and
+	// after, requests waiting for it.
+	loginMu   sync.Mutex
+	loggedIn  *sync.Cond
+	authToken string
+	loginErr  error
+
+	// For the newer API, which uses short-lived access tokens.
+	accountNumber string
+	accessToken   *AccessTokenResponse
+	accessTokenMu sync.Mutex
+	// A copy of accountNumber, to redact from logs.
+	redactedAccount atomic.Value
 }
 
 func GetClient(account_id string) (*Client, error) {
 	rclient := resty.New().EnableTrace().SetDebug(true)
+	account_cache := newAccountCache()
+	rclient.SetTransport(&limitingTransport{
+		base:         rclient.GetClient().Transport,
+		slots:        make(chan struct{}, MaxConcurrentRequests),
+		accountCache: account_cache,
+	})
+
 	client := Client{
-		*rclient,
-		"",
+		Client:       *rclient,
+		RunID:        newRunID(),
+		auditKey:     []byte(NewAuditKey()),
+		cache:        newResponseCache(DefaultCacheTTL),
+		accountCache: account_cache,
 	}
 
+	client.loggedIn = sync.NewCond(&client.loginMu)
 	client.SetHostURL("https://api.mullvad.net")
 
+	// Requests known to contain the account number aren't logged at all, but
+	// it may be in others' responses, e.g. reading the account.
 	client.OnRequestLog(func(rl *resty.RequestLog) error {
+		rl.Body = client.redactAccount(rl.Body)
 		log.Printf("[INFO] Mullvad API request: %s", rl)
 		return nil
 	})
 	client.OnResponseLog(func(rl *resty.ResponseLog) error {
+		rl.Body = client.redactAccount(rl.Body)
 		log.Printf("[DEBUG] Mullvad API response: %s", rl)
 		return nil
 	})
@@ -40,37 +88,129 @@ func GetClient(account_id string) (*Client, error) {
 	}
 
 	client.OnBeforeRequest(func(_ *resty.Client, req *resty.Request) error {
-		if strings.Contains(req.URL, "/accounts/") {
+		if req.URL == "auth/v1/token" {
+			// Not a modification, despite the method
+			return nil
+		}
+
+		if client.ReadOnly && req.Method != http.MethodGet && req.Method != http.MethodHead {
+			log.Printf("[ERROR] Refusing %s %s in read-only mode", req.Method, req.URL)
+			return fmt.Errorf("%w (%s %s)", ErrReadOnly, req.Method, req.URL)
+		}
+
+		return nil
+	})
+
+	client.OnBeforeRequest(func(_ *resty.Client, req *resty.Request) error {
+		if strings.HasPrefix(req.URL, "www/accounts/") {
 			// Logging in, auth not required
 			return nil
 		}
-		for client.AuthToken == "" {
-			// If the `account_id` is not set on the provider,
-			// but instead comes from a `mullvad_account`,
-			// we need to wait until it's read for login.
-			time.Sleep(1)
+		if strings.HasPrefix(req.URL, "www/relays/") || req.URL == "www/cities/" {
+			// Public information, auth not required
+			return nil
+		}
+		if strings.HasPrefix(req.URL, "auth/") {
+			// Getting an access token, auth not required
+			return nil
+		}
+		if req.Header.Get("Authorization") != "" {
+			// Authenticated for another account
+			return nil
+		}
+		auth_token, err := client.waitForLogin()
+		if err != nil {
+			return err
 		}
 
-		req.SetHeader("Authorization", "Token "+client.AuthToken)
+		if strings.HasPrefix(req.URL, "accounts/v1/") {
+			token, err := client.getAccessToken()
+			if err != nil {
+				return err
+			}
+
+			req.SetHeader("Authorization", "Bearer "+token)
+			return nil
+		}
+
+		req.SetHeader("Authorization", "Token "+auth_token)
 		return nil
 	})
 
 	return &client, nil
 }
 
+// IsLoggedIn is whether the client has (successfully) logged in to an account.
+func (c *Client) IsLoggedIn() bool {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	return c.authToken != ""
+}
+
+func (c *Client) waitForLogin() (string, error) {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	for c.authToken == "" {
+		if c.loginErr != nil {
+			// Otherwise we'd wait forever.
+			return "", c.loginErr
+		}
+		// If the `account_id` is not set on the provider,
+		// but instead comes from a `mullvad_account`,
+		// we need to wait until it's read for login.
+		c.loggedIn.Wait()
+	}
+
+	return c.authToken, nil
+}
+
+func (c *Client) setLogin(login *LoginResponse) {
+	c.setAccount(login.Account.Token)
+
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	if login.AuthToken != c.authToken {
+		c.accountCache.invalidate()
+	}
+	c.authToken = login.AuthToken
+	c.loginErr = nil
+	c.loggedIn.Broadcast()
+}
+
+func (c *Client) setLoginErr(err error) {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	c.loginErr = err
+	c.loggedIn.Broadcast()
+}
+
 func (c *Client) Login(account_id string) (*Account, error) {
-	resp, err := c.R().SetResult(LoginResponse{}).Get(fmt.Sprintf("www/accounts/%s/", account_id))
+	// Not logged, since the URL contains the account number.
+	resp, err := c.R().SetDebug(false).SetResult(LoginResponse{}).Get(fmt.Sprintf("www/accounts/%s/", account_id))
 	if err != nil {
+		var url_err *url.Error
+		if errors.As(err, &url_err) {
+			// Without the URL, which contains the account ID.
+			err = errors.New(fmt.Sprintf("Failed to login: %s", url_err.Err))
+		}
+
+		c.setLoginErr(err)
 		return nil, err
 	}
 
 	if resp.StatusCode() != http.StatusOK {
 		log.Printf("[ERROR] %s", resp.Status())
-		return nil, errors.New("Authentication failed, check Mullvad account ID")
+		err := errors.New("Authentication failed, check Mullvad account ID")
+		c.setLoginErr(err)
+		return nil, err
 	}
 
 	login := resp.Result().(*LoginResponse)
-	c.AuthToken = login.AuthToken
+	c.setLogin(login)
 
 	return &login.Account, nil
 }
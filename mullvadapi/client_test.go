@@ -0,0 +1,49 @@
+package mullvadapi
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitForLoginWakesOnLogin(t *testing.T) {
+	client, err := GetClient("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := make(chan string)
+	go func() {
+		auth_token, err := client.waitForLogin()
+		if err != nil {
+			t.Error(err)
+		}
+		result <- auth_token
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	client.setLogin(&LoginResponse{Account{Token: "1234567890123456"}, "token"})
+
+	select {
+	case auth_token := <-result:
+		if auth_token != "token" {
+			t.Errorf("Expected 'token', got %q", auth_token)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Still waiting for login")
+	}
+}
+
+func TestWaitForLoginReturnsLoginError(t *testing.T) {
+	client, err := GetClient("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	login_err := errors.New("Authentication failed")
+	client.setLoginErr(login_err)
+
+	if _, err := client.waitForLogin(); err != login_err {
+		t.Errorf("Expected the login error, got %v", err)
+	}
+}
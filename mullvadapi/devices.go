@@ -0,0 +1,160 @@
+package mullvadapi
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrDeviceNotFound = errors.New("Device not found")
+
+func (c *Client) setAccount(account_number string) {
+	c.accessTokenMu.Lock()
+	defer c.accessTokenMu.Unlock()
+
+	if account_number != c.accountNumber {
+		c.accountNumber = account_number
+		c.accessToken = nil
+		c.redactedAccount.Store(account_number)
+	}
+}
+
+// redactAccount from logged request or response bodies.
+func (c *Client) redactAccount(body string) string {
+	// Not under accessTokenMu, which is held while getting a token.
+	account_number, _ := c.redactedAccount.Load().(string)
+	if account_number == "" {
+		return body
+	}
+
+	return strings.ReplaceAll(body, account_number, "<account number>")
+}
+
+func (c *Client) getAccessToken() (string, error) {
+	c.accessTokenMu.Lock()
+	defer c.accessTokenMu.Unlock()
+
+	if c.accessToken != nil && time.Until(c.accessToken.Expiry) > time.Minute {
+		return c.accessToken.AccessToken, nil
+	}
+
+	access_token, err := c.requestAccessToken(c.accountNumber)
+	if err != nil {
+		return "", err
+	}
+
+	c.accessToken = access_token
+	return c.accessToken.AccessToken, nil
+}
+
+// requestAccessToken for any account, not necessarily the one logged in to.
+func (c *Client) requestAccessToken(account_number string) (*AccessTokenResponse, error) {
+	body := &AccessTokenRequest{
+		account_number,
+	}
+
+	// Not logged, since the body contains the account number (and the
+	// response the token).
+	resp, err := c.R().SetDebug(false).SetBody(body).SetResult(AccessTokenResponse{}).Post("auth/v1/token")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		log.Printf("[ERROR] %s", resp.Status())
+		return nil, errors.New("Failed to get access token, check Mullvad account ID")
+	}
+
+	return resp.Result().(*AccessTokenResponse), nil
+}
+
+func (c *Client) ListDevices() (*[]Device, error) {
+	result, err := c.coalescedGet("accounts/v1/devices", []Device{}, "Failed to read devices")
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*[]Device), nil
+}
+
+func (c *Client) GetDevice(id string) (*Device, error) {
+	devices, err := c.ListDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, device := range *devices {
+		if device.ID == id {
+			return &device, nil
+		}
+	}
+
+	return nil, ErrDeviceNotFound
+}
+
+func (c *Client) AddDevice(public_key string, hijack_dns bool) (device *Device, err error) {
+	defer func() {
+		record := AuditRecord{Operation: "add_device", PublicKey: public_key}
+		if device != nil {
+			record.Device = device.ID
+		}
+		c.audit(record, err)
+	}()
+
+	body := &DeviceRequest{
+		public_key,
+		hijack_dns,
+	}
+
+	resp, err := c.R().SetBody(body).SetResult(Device{}).Post("accounts/v1/devices")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != http.StatusCreated {
+		log.Printf("[ERROR] %s: %s", resp.Status(), resp.Body())
+		return nil, errors.New("Failed to add device")
+	}
+
+	return resp.Result().(*Device), nil
+}
+
+// RotateDeviceKey replaces the device's public key, keeping its addresses.
+func (c *Client) RotateDeviceKey(id string, public_key string) (_ *Device, err error) {
+	defer func() { c.audit(AuditRecord{Operation: "rotate_device_key", Device: id, PublicKey: public_key}, err) }()
+
+	body := &DeviceKeyRequest{
+		public_key,
+	}
+
+	resp, err := c.R().SetBody(body).SetResult(Device{}).Put(fmt.Sprintf("accounts/v1/devices/%s/pubkey", id))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		log.Printf("[ERROR] %s: %s", resp.Status(), resp.Body())
+		return nil, errors.New("Failed to rotate device key")
+	}
+
+	return resp.Result().(*Device), nil
+}
+
+func (c *Client) RemoveDevice(id string) (err error) {
+	defer func() { c.audit(AuditRecord{Operation: "remove_device", Device: id}, err) }()
+
+	resp, err := c.R().Delete(fmt.Sprintf("accounts/v1/devices/%s", id))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != http.StatusNoContent && resp.StatusCode() != http.StatusNotFound {
+		log.Printf("[ERROR] %s: %s", resp.Status(), resp.Body())
+		return errors.New("Failed to remove device")
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package mullvadapi
+
+import (
+	"sort"
+)
+
+func (rc *RelayCounts) add(kind string) {
+	switch kind {
+	case "wireguard":
+		rc.WireGuard++
+	case "openvpn":
+		rc.OpenVpn++
+	case "bridge":
+		rc.Bridge++
+	}
+}
+
+// ListCountries builds the location catalogue from the relay list, since
+// that is the only source of both country and city names with their codes.
+func (c *Client) ListCountries() (*[]Country, error) {
+	relays, err := c.ListRelays("all")
+	if relays == nil {
+		return nil, err
+	}
+
+	countries := make(map[string]*Country)
+	cities := make(map[string]*City)
+	for _, relay := range *relays {
+		country, exists := countries[relay.CountryCode]
+		if !exists {
+			country = &Country{
+				Code: relay.CountryCode,
+				Name: relay.CountryName,
+			}
+			countries[relay.CountryCode] = country
+		}
+		country.add(relay.Type)
+
+		country_city_code := relay.CountryCode + "-" + relay.CityCode
+		city, exists := cities[country_city_code]
+		if !exists {
+			city = &City{
+				CountryCode: relay.CountryCode,
+				CountryName: relay.CountryName,
+				CityCode:    relay.CityCode,
+				Name:        relay.CityName,
+			}
+			cities[country_city_code] = city
+		}
+		city.add(relay.Type)
+	}
+
+	for _, city := range cities {
+		country := countries[city.CountryCode]
+		country.Cities = append(country.Cities, *city)
+	}
+
+	result := make([]Country, 0, len(countries))
+	for _, country := range countries {
+		sort.Slice(country.Cities, func(i, j int) bool {
+			return country.Cities[i].CityCode < country.Cities[j].CityCode
+		})
+		result = append(result, *country)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Code < result[j].Code
+	})
+
+	// Possibly a StaleResponseError
+	return &result, err
+}
@@ -1,5 +1,9 @@
 package mullvadapi
 
+import (
+	"time"
+)
+
 type PortRequest struct {
 	PublicKey       string `json:"pubkey"`
 	CountryCityCode string `json:"city_code"`
@@ -10,6 +14,11 @@ type PortRemoveRequest struct {
 	Port            int    `json:"port"`
 }
 
+type PortAssignRequest struct {
+	PortRemoveRequest
+	PublicKey string `json:"pubkey"`
+}
+
 type PortResponse struct {
 	Port int `json:"port"`
 }
@@ -26,8 +35,9 @@ type WireGuardPeer struct {
 
 type Subscription struct {
 	PaymentMethod string `json:"method"`
-	Status        string `json:"string"`
+	Status        string `json:"status"`
 	IsUnpaid      bool   `json:"unpaid"`
+	RenewalDate   string `json:"renewal_date"`
 }
 
 type Account struct {
@@ -36,7 +46,7 @@ type Account struct {
 	IsActive           bool             `json:"active"`
 	ExpiryDate         string           `json:"expires"`
 	ExpiryUnix         int              `json:"expiry_unix"`
-	_ports             []int            `json:"ports"`
+	Ports              []int            `json:"ports"`
 	ForwardingPorts    []ForwardingPort `json:"city_ports"`
 	MaxForwardingPorts int              `json:"max_ports"`
 	CanAddPorts        bool             `json:"can_add_ports"`
@@ -46,6 +56,27 @@ type Account struct {
 	Subscription       *Subscription    `json:"subscription"`
 }
 
+type RelayCounts struct {
+	WireGuard int
+	OpenVpn   int
+	Bridge    int
+}
+
+type City struct {
+	RelayCounts
+	CountryCode string
+	CountryName string
+	CityCode    string
+	Name        string
+}
+
+type Country struct {
+	RelayCounts
+	Code   string
+	Name   string
+	Cities []City
+}
+
 type CityResponse struct {
 	CountryCityCode string `json:"code"`
 	Name            string `json:"name"`
@@ -64,6 +95,11 @@ type KeyRequest struct {
 	PublicKey string `json:"pubkey"`
 }
 
+type KeyReplaceRequest struct {
+	OldPublicKey string `json:"old"`
+	NewPublicKey string `json:"new"`
+}
+
 type KeyPair struct {
 	PublicKey  string `json:"public"`
 	PrivateKey string `json:"private"`
@@ -105,3 +141,57 @@ type RelayResponse struct {
 	SshFprSha256   string   `json:"ssh_fingerprint_sha256" mapstructure:"ssh_fingerprint_sha256,omitempty"`
 	SshFprMd5      string   `json:"ssh_fingerprint_md5" mapstructure:"ssh_fingerprint_md5,omitempty"`
 }
+
+// AccountResponse is the newer API's view of the account.
+type AccountResponse struct {
+	ID     string    `json:"id"`
+	Expiry time.Time `json:"expiry"`
+	Number string    `json:"number"`
+}
+
+type AccessTokenRequest struct {
+	AccountNumber string `json:"account_number"`
+}
+
+type AccessTokenResponse struct {
+	AccessToken string    `json:"access_token"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+type DeviceRequest struct {
+	PublicKey string `json:"pubkey"`
+	HijackDNS bool   `json:"hijack_dns"`
+}
+
+type DeviceKeyRequest struct {
+	PublicKey string `json:"pubkey"`
+}
+
+type DevicePort struct {
+	ID string `json:"id"`
+}
+
+type Device struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	PublicKey   string       `json:"pubkey"`
+	HijackDNS   bool         `json:"hijack_dns"`
+	Created     string       `json:"created"`
+	IpV4Address string       `json:"ipv4_address"`
+	IpV6Address string       `json:"ipv6_address"`
+	Ports       []DevicePort `json:"ports"`
+}
+
+type VoucherRequest struct {
+	VoucherCode string `json:"voucher_code"`
+}
+
+type VoucherResponse struct {
+	TimeAdded int    `json:"time_added"`
+	NewExpiry string `json:"new_expiry"`
+}
+
+type ErrorResponse struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
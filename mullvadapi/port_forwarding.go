@@ -7,7 +7,9 @@ import (
 	"net/http"
 )
 
-func (c *Client) AddForwardingPort(country_code string, city_code string, maybe_public_key *string) (*int, error) {
+var ErrPortNotFound = errors.New("Port not found")
+
+func (c *Client) AddForwardingPort(country_code string, city_code string, maybe_public_key *string) (added *int, err error) {
 	body := &PortRequest{}
 
 	if maybe_public_key != nil {
@@ -16,6 +18,14 @@ func (c *Client) AddForwardingPort(country_code string, city_code string, maybe_
 
 	body.CountryCityCode = fmt.Sprintf("%s-%s", country_code, city_code)
 
+	defer func() {
+		record := AuditRecord{Operation: "add_forwarding_port", PublicKey: body.PublicKey, Location: body.CountryCityCode}
+		if added != nil {
+			record.Port = *added
+		}
+		c.audit(record, err)
+	}()
+
 	resp, err := c.R().SetBody(body).SetResult(PortResponse{}).Post("www/ports/add/")
 	if err != nil {
 		return nil, err
@@ -31,17 +41,12 @@ func (c *Client) AddForwardingPort(country_code string, city_code string, maybe_
 }
 
 func (c *Client) ListForwardingPorts() (*[]ForwardingPort, error) {
-	resp, err := c.R().SetResult(MeResponse{}).Get("www/me/")
+	result, err := c.coalescedGet("www/me/", MeResponse{}, "Failed to read ports")
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode() != http.StatusOK {
-		log.Printf("[ERROR] %s", resp.Status())
-		return nil, errors.New("Failed to read ports")
-	}
-
-	ports := resp.Result().(*MeResponse).Account.ForwardingPorts
+	ports := result.(*MeResponse).Account.ForwardingPorts
 	return &ports, nil
 }
 
@@ -59,10 +64,42 @@ func (c *Client) GetForwardingPort(country_code string, city_code string, port i
 		}
 	}
 
-	return nil, errors.New("Port not found")
+	return nil, ErrPortNotFound
+}
+
+// AssignForwardingPort (re)assigns an existing port to the given public key,
+// or unassigns it if that's nil, keeping the port number.
+func (c *Client) AssignForwardingPort(country_code string, city_code string, port int, maybe_public_key *string) (err error) {
+	body := &PortAssignRequest{}
+	body.CountryCityCode = fmt.Sprintf("%s-%s", country_code, city_code)
+	body.Port = port
+
+	if maybe_public_key != nil {
+		body.PublicKey = *maybe_public_key
+	}
+
+	defer func() {
+		c.audit(AuditRecord{Operation: "assign_forwarding_port", PublicKey: body.PublicKey, Location: body.CountryCityCode, Port: port}, err)
+	}()
+
+	resp, err := c.R().SetBody(body).Post("www/ports/assign/")
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusNoContent {
+		log.Printf("[ERROR] %s: %s", resp.Status(), resp.Body())
+		return errors.New("Failed to assign forwarding port")
+	}
+
+	return nil
 }
 
-func (c *Client) RemoveForwardingPort(country_code string, city_code string, port int) error {
+func (c *Client) RemoveForwardingPort(country_code string, city_code string, port int) (err error) {
+	defer func() {
+		c.audit(AuditRecord{Operation: "remove_forwarding_port", Location: fmt.Sprintf("%s-%s", country_code, city_code), Port: port}, err)
+	}()
+
 	country_city_code := fmt.Sprintf("%s-%s", country_code, city_code)
 	body := &PortRemoveRequest{
 		country_city_code,
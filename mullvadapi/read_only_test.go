@@ -0,0 +1,73 @@
+package mullvadapi
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestReadOnlyRefusesModification(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/auth/v1/token":
+			w.Write([]byte(`{"access_token": "token", "expiry": "2030-01-01T00:00:00Z"}`))
+		case "/accounts/v1/devices":
+			w.Write([]byte(`[]`))
+		case "/www/wg-pubkeys/list/":
+			w.Write([]byte(`{"keys": [], "max_ports": 5}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	client.ReadOnly = true
+	client.setLogin(&LoginResponse{Account{Token: "1234567890123456"}, "token"})
+
+	modifications := map[string]func() error{
+		"AddWireGuardKey": func() error { return client.AddWireGuardKey("key") },
+		"RotateDeviceKey": func() error {
+			_, err := client.RotateDeviceKey("device", "key")
+			return err
+		},
+		"RemoveDevice": func() error { return client.RemoveDevice("device") },
+	}
+	for name, modify := range modifications {
+		if err := modify(); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("Expected %s to be refused, got %v", name, err)
+		}
+	}
+
+	mu.Lock()
+	if len(requests) != 0 {
+		t.Errorf("Expected no requests to reach the API, got %v", requests)
+	}
+	mu.Unlock()
+
+	// Reading devices requires an access token, despite the POST.
+	if _, err := client.ListDevices(); err != nil {
+		t.Errorf("Expected reading devices to be allowed, got %s", err)
+	}
+
+	if _, err := client.ListWireGuardKeys(); err != nil {
+		t.Errorf("Expected reading keys to be allowed, got %s", err)
+	}
+
+	expected := []string{"POST /auth/v1/token", "GET /accounts/v1/devices", "GET /www/wg-pubkeys/list/"}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, requests)
+	}
+	for i := range expected {
+		if requests[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, requests)
+		}
+	}
+}
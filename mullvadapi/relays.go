@@ -1,22 +1,21 @@
 package mullvadapi
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
-	"log"
-	"net/http"
 )
 
 func (c *Client) ListRelays(kind string) (*[]RelayResponse, error) {
-	resp, err := c.R().SetResult([]RelayResponse{}).Get(fmt.Sprintf("www/relays/%s/", kind))
-	if err != nil {
+	body, err := c.cachedGet(fmt.Sprintf("www/relays/%s/", kind), "Failed to read available relays")
+	if body == nil {
 		return nil, err
 	}
 
-	if resp.StatusCode() != http.StatusOK {
-		log.Printf("[ERROR] %s: %s", resp.Status(), resp.Body())
-		return nil, errors.New("Failed to read available relays")
+	relays := []RelayResponse{}
+	if err := json.Unmarshal(body, &relays); err != nil {
+		return nil, err
 	}
 
-	return resp.Result().(*[]RelayResponse), nil
+	// Possibly a StaleResponseError
+	return &relays, err
 }
@@ -0,0 +1,56 @@
+package mullvadapi
+
+import (
+	"errors"
+	"log"
+	"net/http"
+)
+
+var ErrVoucherInvalid = errors.New("Invalid voucher code")
+var ErrVoucherUsed = errors.New("Voucher code has already been used")
+
+// RedeemVoucher adds the voucher's time to the account, or if account_number
+// is given to that account instead of the one logged in to.
+func (c *Client) RedeemVoucher(voucher_code string, account_number string) (_ *VoucherResponse, err error) {
+	// Not the code itself, which is secret until used.
+	defer func() {
+		c.audit(AuditRecord{Operation: "redeem_voucher", Account: c.hashAccount(account_number)}, err)
+	}()
+
+	body := &VoucherRequest{
+		voucher_code,
+	}
+
+	// Not logged, since the body contains the (secret) code.
+	req := c.R().SetDebug(false).SetBody(body).SetResult(VoucherResponse{}).SetError(ErrorResponse{})
+	if account_number != "" {
+		access_token, err := c.requestAccessToken(account_number)
+		if err != nil {
+			return nil, err
+		}
+
+		req.SetHeader("Authorization", "Bearer "+access_token.AccessToken)
+	}
+
+	resp, err := req.Post("accounts/v1/submit-voucher")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		log.Printf("[ERROR] %s", resp.Status())
+
+		if e, ok := resp.Error().(*ErrorResponse); ok {
+			switch e.Code {
+			case "INVALID_VOUCHER":
+				return nil, ErrVoucherInvalid
+			case "VOUCHER_USED":
+				return nil, ErrVoucherUsed
+			}
+		}
+
+		return nil, errors.New("Failed to redeem voucher")
+	}
+
+	return resp.Result().(*VoucherResponse), nil
+}
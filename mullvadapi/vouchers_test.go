@@ -0,0 +1,106 @@
+package mullvadapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Both the standard logger's and resty's own debug output.
+type capturedLogs struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (l *capturedLogs) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Write(p)
+}
+
+func (l *capturedLogs) Errorf(format string, v ...interface{}) { fmt.Fprintf(l, format, v...) }
+func (l *capturedLogs) Warnf(format string, v ...interface{})  { fmt.Fprintf(l, format, v...) }
+func (l *capturedLogs) Debugf(format string, v ...interface{}) { fmt.Fprintf(l, format, v...) }
+
+func (l *capturedLogs) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.String()
+}
+
+func captureLogs(t *testing.T, client *Client) *capturedLogs {
+	logs := &capturedLogs{}
+	client.SetDebug(true)
+	client.SetLogger(logs)
+
+	output, flags := log.Writer(), log.Flags()
+	log.SetOutput(logs)
+	t.Cleanup(func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+	})
+
+	return logs
+}
+
+func TestRedeemVoucherForAccount(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/auth/v1/token":
+			var body AccessTokenRequest
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Error(err)
+			}
+			w.Write([]byte(`{"access_token": "token-for-` + body.AccountNumber + `", "expiry": "2030-01-01T00:00:00Z"}`))
+
+		case "/accounts/v1/submit-voucher":
+			if auth := r.Header.Get("Authorization"); auth != "Bearer token-for-1234567890123456" {
+				t.Errorf("Unexpected authorization %q", auth)
+			}
+			w.Write([]byte(`{"time_added": 2592000, "new_expiry": "2030-01-01T00:00:00Z"}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	logs := captureLogs(t, client)
+
+	// Without logging in to any account.
+	redemption, err := client.RedeemVoucher("voucher", "1234567890123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(logs.String(), "1234567890123456") {
+		t.Errorf("Expected the account number not to be logged, got:\n%s", logs)
+	}
+
+	if redemption.TimeAdded != 2592000 {
+		t.Errorf("Expected 2592000 seconds added, got %d", redemption.TimeAdded)
+	}
+}
+
+func TestRedeemVoucherDoesNotLogCode(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"time_added": 2592000, "new_expiry": "2030-01-01T00:00:00Z"}`))
+	}))
+	client.setLogin(&LoginResponse{Account{Token: "1234567890123456"}, "token"})
+	logs := captureLogs(t, client)
+
+	if _, err := client.RedeemVoucher("SECRET-VOUCHER-CODE", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(logs.String(), "SECRET-VOUCHER-CODE") {
+		t.Errorf("Expected the voucher code not to be logged, got:\n%s", logs)
+	}
+}
@@ -2,13 +2,56 @@ package mullvadapi
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 )
 
 var ErrKeyNotFound = errors.New("Failed to find key")
 
-func (c *Client) AddWireGuardKey(public_key string) error {
+// AppKeyError is returned on attempting to revoke or replace a key registered
+// by the Mullvad app, unless the client AllowRevokeAppKeys.
+type AppKeyError struct {
+	Key KeyResponse
+	// Operation refused, e.g. "revoke".
+	Operation string
+}
+
+func (e *AppKeyError) Error() string {
+	return fmt.Sprintf(
+		"Refusing to %s key %s registered by the Mullvad app on %s (addresses %s, %s), which may be in use",
+		e.Operation,
+		e.Key.KeyPair.PublicKey,
+		e.Key.Created,
+		e.Key.IpV4Address,
+		e.Key.IpV6Address,
+	)
+}
+
+func (c *Client) checkRevocable(public_key string, operation string) error {
+	if c.AllowRevokeAppKeys {
+		return nil
+	}
+
+	key, err := c.GetWireGuardKey(public_key)
+	if err != nil {
+		if err == ErrKeyNotFound {
+			return nil
+		}
+
+		return err
+	}
+
+	if key.WasAppRegistered {
+		return &AppKeyError{*key, operation}
+	}
+
+	return nil
+}
+
+func (c *Client) AddWireGuardKey(public_key string) (err error) {
+	defer func() { c.audit(AuditRecord{Operation: "add_wireguard_key", PublicKey: public_key}, err) }()
+
 	body := &KeyRequest{
 		public_key,
 	}
@@ -29,18 +72,12 @@ func (c *Client) AddWireGuardKey(public_key string) error {
 }
 
 func (c *Client) ListWireGuardKeys() (*KeyListResponse, error) {
-	resp, err := c.R().SetResult(KeyListResponse{}).Get("www/wg-pubkeys/list/")
+	result, err := c.coalescedGet("www/wg-pubkeys/list/", KeyListResponse{}, "Failed to read registered keys")
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode() != http.StatusOK {
-		log.Printf("[ERROR] %s: %s", resp.Status(), resp.Body())
-		return nil, errors.New("Failed to read registered keys")
-	}
-
-	result := resp.Result().(*KeyListResponse)
-	return result, nil
+	return result.(*KeyListResponse), nil
 }
 
 func (c *Client) GetWireGuardKey(public_key string) (*KeyResponse, error) {
@@ -58,7 +95,42 @@ func (c *Client) GetWireGuardKey(public_key string) (*KeyResponse, error) {
 	return nil, ErrKeyNotFound
 }
 
-func (c *Client) RevokeWireGuardKey(public_key string) error {
+// ReplaceWireGuardKey swaps a registered key for a new one, which keeps the
+// addresses and forwarded ports of the old.
+func (c *Client) ReplaceWireGuardKey(old_public_key string, new_public_key string) (_ *KeyResponse, err error) {
+	defer func() {
+		c.audit(AuditRecord{Operation: "replace_wireguard_key", PublicKey: new_public_key, OldKey: old_public_key}, err)
+	}()
+
+	if err := c.checkRevocable(old_public_key, "replace"); err != nil {
+		return nil, err
+	}
+
+	body := &KeyReplaceRequest{
+		old_public_key,
+		new_public_key,
+	}
+
+	resp, err := c.R().SetBody(body).SetResult(KeyResponse{}).Post("www/wg-pubkeys/replace/")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
+		log.Printf("[ERROR] %s: %s", resp.Status(), resp.Body())
+		return nil, errors.New("Failed to replace public key")
+	}
+
+	return resp.Result().(*KeyResponse), nil
+}
+
+func (c *Client) RevokeWireGuardKey(public_key string) (err error) {
+	defer func() { c.audit(AuditRecord{Operation: "revoke_wireguard_key", PublicKey: public_key}, err) }()
+
+	if err := c.checkRevocable(public_key, "revoke"); err != nil {
+		return err
+	}
+
 	body := &KeyRequest{
 		public_key,
 	}
@@ -0,0 +1,34 @@
+package mullvadapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAppKeysAreProtected(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/www/wg-pubkeys/list/" {
+			t.Errorf("Unexpected %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"keys": [{"key": {"public": "app-key"}, "app": true}]}`))
+	}))
+	client.setLogin(&LoginResponse{Account{Token: "1234567890123456"}, "token"})
+
+	for operation, mutate := range map[string]func() error{
+		"revoke":  func() error { return client.RevokeWireGuardKey("app-key") },
+		"replace": func() error { _, err := client.ReplaceWireGuardKey("app-key", "new-key"); return err },
+	} {
+		var app_key *AppKeyError
+		if err := mutate(); !errors.As(err, &app_key) {
+			t.Errorf("Expected %s to be refused, got %v", operation, err)
+		} else if !strings.HasPrefix(err.Error(), "Refusing to "+operation+" key app-key") {
+			t.Errorf("Expected the error to name the %s, got: %s", operation, err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"github.com/OJFord/terraform-provider-mullvad/mullvadapi"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// installationAuditKey is the key with which to HMAC account numbers in audit
+// records if `audit_hash_key` is not set, generated once per installation and
+// kept in the user's config directory, apart from the log.
+func installationAuditKey() (string, error) {
+	config_dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(config_dir, "terraform-provider-mullvad", "audit_key")
+	if key, err := os.ReadFile(path); err == nil {
+		if strings.TrimSpace(string(key)) == "" {
+			// Or it's being written concurrently.
+			return "", errors.New(fmt.Sprintf("%s is empty", path))
+		}
+
+		return strings.TrimSpace(string(key)), nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+
+	// Exclusively, in case another run is doing the same.
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return installationAuditKey()
+	} else if err != nil {
+		return "", err
+	}
+
+	key := mullvadapi.NewAuditKey()
+	if _, err := f.WriteString(key + "\n"); err != nil {
+		f.Close()
+		return "", err
+	}
+
+	return key, f.Close()
+}
@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+const accountIdEnvVar = "MULLVAD_ACCOUNT_ID"
+
+// getAccountId returns the (compacted) account ID and where it came from, or
+// an empty ID if none is configured. At most one of `account_id`,
+// `account_id_file`, and `account_id_command` may be; failing all of them,
+// it's read from the environment. Errors never include the secret itself.
+func getAccountId(ctx context.Context, d *schema.ResourceData) (string, string, error) {
+	if account_id := d.Get("account_id").(string); account_id != "" {
+		return compactAccountNumber(account_id), "account_id", nil
+	}
+
+	if path := d.Get("account_id_file").(string); path != "" {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return "", "", errors.New(fmt.Sprintf("Failed to read account_id_file: %s", err))
+		}
+
+		return nonEmptyAccountId(string(contents), "account_id_file")
+	}
+
+	if command := d.Get("account_id_command").(string); command != "" {
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", "-c", command)
+		}
+
+		// Not its stderr, which may echo the secret.
+		var stdout bytes.Buffer
+		cmd.Stdout = &stdout
+
+		if err := cmd.Run(); err != nil {
+			return "", "", errors.New(fmt.Sprintf("Failed to run account_id_command: %s", err))
+		}
+
+		return nonEmptyAccountId(stdout.String(), "account_id_command")
+	}
+
+	if account_id := os.Getenv(accountIdEnvVar); account_id != "" {
+		return compactAccountNumber(account_id), accountIdEnvVar, nil
+	}
+
+	return "", "", nil
+}
+
+func nonEmptyAccountId(account_id string, source string) (string, string, error) {
+	if account_id = compactAccountNumber(account_id); account_id == "" {
+		return "", "", errors.New(fmt.Sprintf("No account ID given by %s", source))
+	}
+
+	return account_id, source, nil
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func testAccountIdConfig(t *testing.T, raw map[string]interface{}) *schema.ResourceData {
+	return schema.TestResourceDataRaw(t, Provider().Schema, raw)
+}
+
+func writeAccountIdFile(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "account_id")
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestGetAccountIdPrecedence(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("account_id_command uses POSIX shell syntax here")
+	}
+
+	t.Setenv(accountIdEnvVar, "4444 4444 4444 4444")
+	file := writeAccountIdFile(t, "2222 2222 2222 2222\n")
+
+	cases := []struct {
+		raw    map[string]interface{}
+		id     string
+		source string
+	}{
+		{map[string]interface{}{"account_id": "1111111111111111", "account_id_file": file, "account_id_command": "echo 3333333333333333"}, "1111111111111111", "account_id"},
+		{map[string]interface{}{"account_id_file": file, "account_id_command": "echo 3333333333333333"}, "2222222222222222", "account_id_file"},
+		{map[string]interface{}{"account_id_command": "echo 3333333333333333"}, "3333333333333333", "account_id_command"},
+		{map[string]interface{}{}, "4444444444444444", accountIdEnvVar},
+	}
+
+	for _, c := range cases {
+		id, source, err := getAccountId(context.Background(), testAccountIdConfig(t, c.raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id != c.id || source != c.source {
+			t.Errorf("Expected %s from %s, got %s from %s", c.id, c.source, id, source)
+		}
+	}
+}
+
+func TestGetAccountIdUnset(t *testing.T) {
+	t.Setenv(accountIdEnvVar, "")
+
+	id, source, err := getAccountId(context.Background(), testAccountIdConfig(t, map[string]interface{}{}))
+	if err != nil || id != "" || source != "" {
+		t.Errorf("Expected no account ID, got %q from %q (%v)", id, source, err)
+	}
+}
+
+func TestGetAccountIdEmpty(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("account_id_command uses POSIX shell syntax here")
+	}
+
+	t.Setenv(accountIdEnvVar, "4444444444444444")
+
+	cases := map[string]map[string]interface{}{
+		"account_id_file":    {"account_id_file": writeAccountIdFile(t, " \n")},
+		"account_id_command": {"account_id_command": "true"},
+	}
+
+	for source, raw := range cases {
+		_, _, err := getAccountId(context.Background(), testAccountIdConfig(t, raw))
+		if expected := "No account ID given by " + source; err == nil || err.Error() != expected {
+			t.Errorf("Expected %q, got %v", expected, err)
+		}
+	}
+}
+
+func TestGetAccountIdCommandFailureOmitsOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("account_id_command uses POSIX shell syntax here")
+	}
+
+	raw := map[string]interface{}{"account_id_command": "echo 5555555555555555; echo 5555555555555555 >&2; exit 3"}
+	_, _, err := getAccountId(context.Background(), testAccountIdConfig(t, raw))
+	if err == nil {
+		t.Fatal("Expected the command to fail")
+	}
+
+	if strings.Contains(err.Error(), "5555555555555555") {
+		t.Errorf("Expected the command's output not to be included, got %s", err)
+	}
+
+	if !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("Expected the command's exit status, got %s", err)
+	}
+}
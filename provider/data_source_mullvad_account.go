@@ -3,18 +3,112 @@ package provider
 import (
 	"github.com/OJFord/terraform-provider-mullvad/mullvadapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"log"
+	"strings"
 )
 
+var forwardingPortSchema = map[string]*schema.Schema{
+	"city_code": {
+		Description: "The city code of the port's location.",
+		Computed:    true,
+		Type:        schema.TypeString,
+	},
+	"country_code": {
+		Description: "The country code of the port's location.",
+		Computed:    true,
+		Type:        schema.TypeString,
+	},
+	"port": {
+		Description: "The port number.",
+		Computed:    true,
+		Type:        schema.TypeInt,
+	},
+	"public_key": {
+		Description: "The public key of the WireGuard peer to which the port is assigned, if any.",
+		Computed:    true,
+		Type:        schema.TypeString,
+	},
+}
+
+var wireguardPeerSchema = map[string]*schema.Schema{
+	"created": {
+		Description: "The date the peer was registered.",
+		Computed:    true,
+		Type:        schema.TypeString,
+	},
+	"ipv4_address": {
+		Description: "The IPv4 address the peer may use.",
+		Computed:    true,
+		Type:        schema.TypeString,
+	},
+	"ipv6_address": {
+		Description: "The IPv6 address the peer may use.",
+		Computed:    true,
+		Type:        schema.TypeString,
+	},
+	"is_app_registered": {
+		Description: "Whether the peer was registered by the Mullvad app.",
+		Computed:    true,
+		Type:        schema.TypeBool,
+	},
+	"ports": {
+		Description: "The ports forwarded for the peer.",
+		Computed:    true,
+		Elem: &schema.Schema{
+			Type: schema.TypeInt,
+		},
+		Type: schema.TypeList,
+	},
+	"public_key": {
+		Description: "The public key of the peer.",
+		Computed:    true,
+		Type:        schema.TypeString,
+	},
+}
+
 var accountSchema = map[string]*schema.Schema{
+	"account_number": {
+		Description: "The (secret) Mullvad account number.",
+		Computed:    true,
+		Sensitive:   true,
+		Type:        schema.TypeString,
+	},
+	"can_add_forwarding_ports": {
+		Description: "Whether another forwarding port may be added.",
+		Computed:    true,
+		Type:        schema.TypeBool,
+	},
+	"can_add_wireguard_peers": {
+		Description: "Whether another WireGuard peer may be registered.",
+		Computed:    true,
+		Type:        schema.TypeBool,
+	},
+	"days_remaining": {
+		Description: "Number of whole days remaining until the account expires, without new payment.",
+		Computed:    true,
+		Type:        schema.TypeInt,
+	},
 	"expires_at": {
 		Description: "Timestamp (RFC3339) at which the account expires, without new payment.",
 		Computed:    true,
 		Type:        schema.TypeString,
 	},
+	"expires_unix": {
+		Description: "Unix timestamp at which the account expires, without new payment.",
+		Computed:    true,
+		Type:        schema.TypeInt,
+	},
+	"forwarding_ports": {
+		Description: "The ports forwarded on the account.",
+		Computed:    true,
+		Elem: &schema.Resource{
+			Schema: forwardingPortSchema,
+		},
+		Type: schema.TypeList,
+	},
 	"id": {
-		Description: "The (secret) Mullvad account ID.",
+		Description: "The Mullvad account's (non-secret) UUID.",
 		Computed:    true,
-		Sensitive:   true,
 		Type:        schema.TypeString,
 	},
 	"is_active": {
@@ -42,6 +136,24 @@ var accountSchema = map[string]*schema.Schema{
 		Computed:    true,
 		Type:        schema.TypeString,
 	},
+	"subscription_renewal_date": {
+		Description: "Date on which the subscription is next due to renew, if there is one.",
+		Computed:    true,
+		Type:        schema.TypeString,
+	},
+	"subscription_status": {
+		Description: "Status of the subscription, if there is one.",
+		Computed:    true,
+		Type:        schema.TypeString,
+	},
+	"wireguard_peers": {
+		Description: "The WireGuard peers registered on the account.",
+		Computed:    true,
+		Elem: &schema.Resource{
+			Schema: wireguardPeerSchema,
+		},
+		Type: schema.TypeList,
+	},
 }
 
 func dataSourceMullvadAccount() *schema.Resource {
@@ -49,29 +161,84 @@ func dataSourceMullvadAccount() *schema.Resource {
 		Description: "Information about the Mullvad account.",
 		Schema:      accountSchema,
 
-		Read: dataSourceMullvadAccountRead,
+		ReadContext: checkExpiry(dataSourceMullvadAccountRead),
 	}
 }
 
-func populateAccountResource(d *schema.ResourceData, acc *mullvadapi.Account) {
-	d.SetId(acc.Token)
+func populateAccountResource(d *schema.ResourceData, client *mullvadapi.Client, acc *mullvadapi.Account) error {
+	// Not the account number, which would be shown in plans, logs, etc.
+	id, err := client.GetAccountID()
+	if err != nil {
+		return err
+	}
+
+	d.SetId(id)
+	d.Set("account_number", acc.Token)
+	d.Set("can_add_forwarding_ports", acc.CanAddPorts)
+	d.Set("can_add_wireguard_peers", acc.CanAddWgPeers)
 	d.Set("expires_at", acc.ExpiryDate)
+	if expiry, err := accountExpiry(acc); err != nil {
+		// Only an error if the provider's configured to check it.
+		log.Printf("[WARN] Unknown expiry '%s': %s", acc.ExpiryDate, err)
+		d.Set("days_remaining", nil)
+		d.Set("expires_unix", nil)
+	} else {
+		d.Set("days_remaining", daysRemaining(expiry))
+		d.Set("expires_unix", expiry.Unix())
+	}
+	d.Set("forwarding_ports", flattenForwardingPorts(acc.ForwardingPorts))
 	d.Set("is_active", acc.IsActive)
 	d.Set("is_subscription_unpaid", acc.Subscription == nil || acc.Subscription.IsUnpaid)
 	d.Set("max_forwarding_ports", acc.MaxForwardingPorts)
 	d.Set("max_wireguard_peers", acc.MaxWireGuardPeers)
+	d.Set("wireguard_peers", flattenWireGuardPeers(acc.WireGuardPeers))
 
 	if acc.Subscription != nil {
 		d.Set("subscription_method", acc.Subscription.PaymentMethod)
+		d.Set("subscription_renewal_date", acc.Subscription.RenewalDate)
+		d.Set("subscription_status", acc.Subscription.Status)
 	}
+
+	return nil
+}
+
+func flattenForwardingPorts(ports []mullvadapi.ForwardingPort) []map[string]interface{} {
+	flattened := make([]map[string]interface{}, 0, len(ports))
+	for _, port := range ports {
+		country_code, city_code, _ := strings.Cut(port.CountryCityCode, "-")
+		flattened = append(flattened, map[string]interface{}{
+			"city_code":    city_code,
+			"country_code": country_code,
+			"port":         port.Port,
+			"public_key":   port.PublicKey,
+		})
+	}
+
+	return flattened
+}
+
+func flattenWireGuardPeers(peers []mullvadapi.WireGuardPeer) []map[string]interface{} {
+	flattened := make([]map[string]interface{}, 0, len(peers))
+	for _, peer := range peers {
+		flattened = append(flattened, map[string]interface{}{
+			"created":           peer.Created,
+			"ipv4_address":      peer.IpV4Address,
+			"ipv6_address":      peer.IpV6Address,
+			"is_app_registered": peer.WasAppRegistered,
+			"ports":             peer.Ports,
+			"public_key":        peer.KeyPair.PublicKey,
+		})
+	}
+
+	return flattened
 }
 
 func dataSourceMullvadAccountRead(d *schema.ResourceData, m interface{}) error {
-	acc, err := m.(*mullvadapi.Client).GetAccount()
+	client := m.(*providerMeta).Client
+	acc, err := client.GetAccount()
 	if err != nil {
 		return err
 	}
 
-	populateAccountResource(d, acc)
-	return nil
+	return populateAccountResource(d, client, acc)
 }
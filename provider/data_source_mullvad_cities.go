@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"strings"
+)
+
+func dataSourceMullvadCities() *schema.Resource {
+	return &schema.Resource{
+		Description: "Optionally filtered list of the cities in which Mullvad has relays.",
+
+		ReadContext: warnIfStale(dataSourceMullvadCitiesRead),
+		Schema: map[string]*schema.Schema{
+			"country_code": {
+				Description: "Country code (ISO3166-1 Alpha-2) to which the returned cities should be limited.",
+				Optional:    true,
+				ForceNew:    true,
+				Type:        schema.TypeString,
+			},
+
+			"cities": {
+				Description: "List of the (filtered) cities, ordered by country code then city code.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: withRelayCounts(map[string]*schema.Schema{
+						"country_code": {
+							Description: "Country code (ISO3166-1 Alpha-2) in which the city is located.",
+							Computed:    true,
+							Type:        schema.TypeString,
+						},
+						"country_name": {
+							Description: "Name of the country in which the city is located.",
+							Computed:    true,
+							Type:        schema.TypeString,
+						},
+						"city_code": {
+							Description: "Mullvad's code for the city.",
+							Computed:    true,
+							Type:        schema.TypeString,
+						},
+						"city_name": {
+							Description: "Name of the city.",
+							Computed:    true,
+							Type:        schema.TypeString,
+						},
+					}),
+				},
+			},
+		},
+	}
+}
+
+func dataSourceMullvadCitiesRead(d *schema.ResourceData, m interface{}) error {
+	countries, err := m.(*providerMeta).Client.ListCountries()
+	if countries == nil {
+		return err
+	}
+
+	country_code := strings.ToLower(d.Get("country_code").(string))
+
+	result := make([]map[string]interface{}, 0)
+	for _, country := range *countries {
+		if country_code != "" && country.Code != country_code {
+			continue
+		}
+
+		for _, city := range country.Cities {
+			result = append(result, flattenRelayCounts(map[string]interface{}{
+				"country_code": city.CountryCode,
+				"country_name": city.CountryName,
+				"city_code":    city.CityCode,
+				"city_name":    city.Name,
+			}, city.RelayCounts))
+		}
+	}
+
+	if country_code != "" && len(result) == 0 {
+		return errors.New(fmt.Sprintf("No cities found for country '%s'", country_code))
+	}
+
+	if country_code == "" {
+		d.SetId("cities")
+	} else {
+		d.SetId(country_code)
+	}
+	d.Set("cities", result)
+	return err
+}
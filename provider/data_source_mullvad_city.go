@@ -5,52 +5,141 @@ import (
 	"fmt"
 	"github.com/OJFord/terraform-provider-mullvad/mullvadapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
 	"strings"
+	"unicode"
 )
 
+// Alternative (normalised) names by which a city may be known, mapped to the
+// (normalised) name Mullvad uses for it.
+var cityAliases = map[string]string{
+	"bucuresti":         "bucharest",
+	"frankfurt am main": "frankfurt",
+	"goteborg":          "gothenburg",
+	"kiev":              "kyiv",
+	"københavn":         "copenhagen",
+	"la":                "los angeles",
+	"new york city":     "new york",
+	"nyc":               "new york",
+	"praha":             "prague",
+	"warszawa":          "warsaw",
+	"wien":              "vienna",
+}
+
 func dataSourceMullvadCity() *schema.Resource {
 	return &schema.Resource{
-		Description: "Mullvad location codes by city name.",
+		Description: "Mullvad location codes by city name, or city name by location codes.",
 
-		Read: dataSourceMullvadCityRead,
+		ReadContext: warnIfStale(dataSourceMullvadCityRead),
 		Schema: map[string]*schema.Schema{
 			"name": {
-				Description: "Name of the city to lookup.",
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
+				Description:  "Name of the city to lookup. Matching is case-insensitive, ignores accents and any state suffix (e.g. `\"New York\"` matches `\"New York, NY\"`), and accepts some common alternative names.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"name", "city_code"},
 			},
 
 			"country_code": {
-				Description: "The ISO3166-1 Alpha-2 country code.",
+				Description: "The ISO3166-1 Alpha-2 country code. May be given to disambiguate a `name` found in multiple countries, and is required with `city_code`.",
 				Type:        schema.TypeString,
+				Optional:    true,
 				Computed:    true,
+				ForceNew:    true,
 			},
 
 			"city_code": {
-				Description: "The 3-letter code used to refer to the city in Mullvad's API.",
-				Type:        schema.TypeString,
-				Computed:    true,
+				Description:  "The 3-letter code used to refer to the city in Mullvad's API. May be given (with `country_code`) instead of `name` to lookup the city's name.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"name", "city_code"},
+				RequiredWith: []string{"country_code"},
 			},
 		},
 	}
 }
 
-func dataSourceMullvadCityRead(d *schema.ResourceData, m interface{}) error {
-	cities, err := m.(*mullvadapi.Client).ListCities()
+func normaliseCityName(name string) string {
+	t := transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	normalised, _, err := transform.String(t, name)
 	if err != nil {
+		normalised = name
+	}
+
+	return strings.Join(strings.Fields(strings.ToLower(normalised)), " ")
+}
+
+func cityNameMatches(city mullvadapi.CityResponse, name string) bool {
+	full := normaliseCityName(city.Name)
+	short, _, _ := strings.Cut(full, ",")
+	return name == full || name == strings.TrimSpace(short)
+}
+
+func setCity(d *schema.ResourceData, city mullvadapi.CityResponse) {
+	d.SetId(city.CountryCityCode)
+	codes := strings.Split(city.CountryCityCode, "-")
+	d.Set("name", city.Name)
+	d.Set("country_code", codes[0])
+	d.Set("city_code", codes[1])
+}
+
+func dataSourceMullvadCityRead(d *schema.ResourceData, m interface{}) error {
+	cities, err := m.(*providerMeta).Client.ListCities()
+	if cities == nil {
 		return err
 	}
 
+	country_code := strings.ToLower(d.Get("country_code").(string))
+
+	if city_code, ok := d.GetOk("city_code"); ok {
+		country_city_code := fmt.Sprintf("%s-%s", country_code, strings.ToLower(city_code.(string)))
+		for _, city := range *cities {
+			if city.CountryCityCode == country_city_code {
+				setCity(d, city)
+				return err
+			}
+		}
+
+		return errors.New(fmt.Sprintf("No match for city code '%s'", country_city_code))
+	}
+
+	name := normaliseCityName(d.Get("name").(string))
+	if alias, exists := cityAliases[name]; exists {
+		name = alias
+	}
+
+	candidates := make([]mullvadapi.CityResponse, 0)
 	for _, city := range *cities {
-		if city.Name == d.Get("name").(string) {
-			d.SetId(city.CountryCityCode)
-			codes := strings.Split(city.CountryCityCode, "-")
-			d.Set("country_code", codes[0])
-			d.Set("city_code", codes[1])
-			return nil
+		if country_code != "" && !strings.HasPrefix(city.CountryCityCode, country_code+"-") {
+			continue
 		}
+
+		if cityNameMatches(city, name) {
+			candidates = append(candidates, city)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return errors.New(fmt.Sprintf("No match for city '%s'", d.Get("name")))
+	case 1:
+		setCity(d, candidates[0])
+		return err
+	}
+
+	descriptions := make([]string, 0, len(candidates))
+	for _, city := range candidates {
+		descriptions = append(descriptions, fmt.Sprintf("%s (%s)", city.Name, city.CountryCityCode))
 	}
 
-	return errors.New(fmt.Sprintf("No match for city '%s'", d.Get("name")))
+	return errors.New(fmt.Sprintf(
+		"City '%s' is ambiguous, set `country_code` to choose between: %s",
+		d.Get("name"),
+		strings.Join(descriptions, ", "),
+	))
 }
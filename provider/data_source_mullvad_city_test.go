@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"github.com/OJFord/terraform-provider-mullvad/mullvadapi"
+	"testing"
+)
+
+func TestNormaliseCityName(t *testing.T) {
+	cases := map[string]string{
+		"London":            "london",
+		"  New   York  ":    "new york",
+		"Zürich":            "zurich",
+		"São Paulo":         "sao paulo",
+		"Göteborg":          "goteborg",
+		"København":         "københavn",
+		"Frankfurt AM Main": "frankfurt am main",
+	}
+
+	for name, expected := range cases {
+		if normalised := normaliseCityName(name); normalised != expected {
+			t.Errorf("normaliseCityName(%q) = %q, expected %q", name, normalised, expected)
+		}
+	}
+}
+
+func TestCityAliasesAreNormalised(t *testing.T) {
+	for alias, name := range cityAliases {
+		if normaliseCityName(alias) != alias {
+			t.Errorf("Alias %q would never match, since names are normalised to %q", alias, normaliseCityName(alias))
+		}
+
+		if normaliseCityName(name) != name {
+			t.Errorf("Alias %q is for %q, which would never match the normalised %q", alias, name, normaliseCityName(name))
+		}
+	}
+}
+
+func TestCityNameMatches(t *testing.T) {
+	city := mullvadapi.CityResponse{CountryCityCode: "us-dal", Name: "Dallas, TX"}
+
+	for _, name := range []string{"dallas, tx", "dallas"} {
+		if !cityNameMatches(city, name) {
+			t.Errorf("Expected %q to match %q", name, city.Name)
+		}
+	}
+
+	if cityNameMatches(city, "dal") {
+		t.Errorf("Expected the city code not to match %q", city.Name)
+	}
+}
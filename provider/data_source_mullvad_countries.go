@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"github.com/OJFord/terraform-provider-mullvad/mullvadapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var relayCountsSchema = map[string]*schema.Schema{
+	"bridge_relays": {
+		Description: "Number of bridge relays at the location.",
+		Computed:    true,
+		Type:        schema.TypeInt,
+	},
+	"openvpn_relays": {
+		Description: "Number of OpenVPN relays at the location.",
+		Computed:    true,
+		Type:        schema.TypeInt,
+	},
+	"wireguard_relays": {
+		Description: "Number of WireGuard relays at the location.",
+		Computed:    true,
+		Type:        schema.TypeInt,
+	},
+}
+
+func withRelayCounts(s map[string]*schema.Schema) map[string]*schema.Schema {
+	for k, v := range relayCountsSchema {
+		s[k] = v
+	}
+	return s
+}
+
+func flattenRelayCounts(m map[string]interface{}, rc mullvadapi.RelayCounts) map[string]interface{} {
+	m["bridge_relays"] = rc.Bridge
+	m["openvpn_relays"] = rc.OpenVpn
+	m["wireguard_relays"] = rc.WireGuard
+	return m
+}
+
+func dataSourceMullvadCountries() *schema.Resource {
+	return &schema.Resource{
+		Description: "List of the countries in which Mullvad has relays.",
+
+		ReadContext: warnIfStale(dataSourceMullvadCountriesRead),
+		Schema: map[string]*schema.Schema{
+			"countries": {
+				Description: "List of countries, ordered by country code.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: withRelayCounts(map[string]*schema.Schema{
+						"code": {
+							Description: "The ISO3166-1 Alpha-2 country code.",
+							Computed:    true,
+							Type:        schema.TypeString,
+						},
+						"name": {
+							Description: "Name of the country.",
+							Computed:    true,
+							Type:        schema.TypeString,
+						},
+						"city_codes": {
+							Description: "Mullvad's codes for the cities in the country which have relays.",
+							Computed:    true,
+							Type:        schema.TypeList,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					}),
+				},
+			},
+		},
+	}
+}
+
+func dataSourceMullvadCountriesRead(d *schema.ResourceData, m interface{}) error {
+	countries, err := m.(*providerMeta).Client.ListCountries()
+	if countries == nil {
+		return err
+	}
+
+	result := make([]map[string]interface{}, 0, len(*countries))
+	for _, country := range *countries {
+		city_codes := make([]string, 0, len(country.Cities))
+		for _, city := range country.Cities {
+			city_codes = append(city_codes, city.CityCode)
+		}
+
+		result = append(result, flattenRelayCounts(map[string]interface{}{
+			"code":       country.Code,
+			"name":       country.Name,
+			"city_codes": city_codes,
+		}, country.RelayCounts))
+	}
+
+	d.SetId("countries")
+	d.Set("countries", result)
+	return err
+}
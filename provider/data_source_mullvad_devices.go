@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceMullvadDevices() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"id": {
+			Description: "The device's ID.",
+			Computed:    true,
+			Type:        schema.TypeString,
+		},
+	}
+	for k, v := range deviceSchema {
+		s[k] = v
+	}
+
+	return &schema.Resource{
+		Description: "List of the devices on the Mullvad account.",
+
+		Read: dataSourceMullvadDevicesRead,
+		Schema: map[string]*schema.Schema{
+			"devices": {
+				Description: "List of the devices.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: s,
+				},
+			},
+		},
+	}
+}
+
+func dataSourceMullvadDevicesRead(d *schema.ResourceData, m interface{}) error {
+	devices, err := m.(*providerMeta).Client.ListDevices()
+	if err != nil {
+		return err
+	}
+
+	result := make([]map[string]interface{}, 0, len(*devices))
+	for _, device := range *devices {
+		result = append(result, flattenDevice(&device))
+	}
+
+	d.SetId("devices")
+	d.Set("devices", result)
+	return nil
+}
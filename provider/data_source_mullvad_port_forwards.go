@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"github.com/OJFord/terraform-provider-mullvad/mullvadapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"strings"
+)
+
+const (
+	assignmentAny        = "any"
+	assignmentAssigned   = "assigned"
+	assignmentUnassigned = "unassigned"
+)
+
+func assignmentFilterSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Description: fmt.Sprintf("%s One of `%s` (the default), `%s`, or `%s`.", description, assignmentAny, assignmentAssigned, assignmentUnassigned),
+		Default:     assignmentAny,
+		Optional:    true,
+		Type:        schema.TypeString,
+		ValidateFunc: func(v interface{}, k string) ([]string, []error) {
+			switch v.(string) {
+			case assignmentAny, assignmentAssigned, assignmentUnassigned:
+				return nil, nil
+			}
+
+			return nil, []error{errors.New(fmt.Sprintf("%s must be one of '%s', '%s', or '%s', got '%s'", k, assignmentAny, assignmentAssigned, assignmentUnassigned, v))}
+		},
+	}
+}
+
+func matchesAssignment(assignment string, assigned bool) bool {
+	return assignment == assignmentAny || (assignment == assignmentAssigned) == assigned
+}
+
+func dataSourceMullvadPortForwards() *schema.Resource {
+	return &schema.Resource{
+		Description: "List of the ports forwarded on the Mullvad account, optionally filtered.",
+
+		Read: dataSourceMullvadPortForwardsRead,
+		Schema: map[string]*schema.Schema{
+			"assignment": assignmentFilterSchema("Whether to list only ports assigned to a WireGuard peer, or only those not."),
+			"city_code": {
+				Description: "If set, only ports in this city are listed.",
+				Optional:    true,
+				Type:        schema.TypeString,
+			},
+			"country_code": {
+				Description: "If set, only ports in this country are listed.",
+				Optional:    true,
+				Type:        schema.TypeString,
+			},
+			"port_forwards": {
+				Description: "List of the ports forwarded.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: forwardingPortSchema,
+				},
+				Type: schema.TypeList,
+			},
+			"public_key": {
+				Description: "If set, only ports assigned to the WireGuard peer with this public key are listed.",
+				Optional:    true,
+				Type:        schema.TypeString,
+			},
+		},
+	}
+}
+
+func dataSourceMullvadPortForwardsRead(d *schema.ResourceData, m interface{}) error {
+	port_forwards, err := m.(*providerMeta).Client.ListForwardingPorts()
+	if err != nil {
+		return err
+	}
+
+	assignment := d.Get("assignment").(string)
+	city_code := d.Get("city_code").(string)
+	country_code := d.Get("country_code").(string)
+	public_key := d.Get("public_key").(string)
+
+	filtered := make([]mullvadapi.ForwardingPort, 0, len(*port_forwards))
+	for _, port_forward := range *port_forwards {
+		port_country_code, port_city_code, _ := strings.Cut(port_forward.CountryCityCode, "-")
+
+		if country_code != "" && port_country_code != country_code {
+			continue
+		}
+
+		if city_code != "" && port_city_code != city_code {
+			continue
+		}
+
+		if public_key != "" && port_forward.PublicKey != public_key {
+			continue
+		}
+
+		if !matchesAssignment(assignment, port_forward.PublicKey != "") {
+			continue
+		}
+
+		filtered = append(filtered, port_forward)
+	}
+
+	d.SetId("port_forwards")
+	d.Set("port_forwards", flattenForwardingPorts(filtered))
+	return nil
+}
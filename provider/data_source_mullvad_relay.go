@@ -2,7 +2,6 @@ package provider
 
 import (
 	"errors"
-	"github.com/OJFord/terraform-provider-mullvad/mullvadapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/mitchellh/mapstructure"
 	"log"
@@ -12,7 +11,7 @@ func dataSourceMullvadRelay() *schema.Resource {
 	return &schema.Resource{
 		Description: "Optionally filtered list of Mullvad servers.",
 
-		Read: dataSourceMullvadRelayRead,
+		ReadContext: warnIfStale(dataSourceMullvadRelayRead),
 		Schema: map[string]*schema.Schema{
 			"filter": {
 				Description: "Filter to apply to the available relays.",
@@ -171,8 +170,8 @@ func dataSourceMullvadRelayRead(d *schema.ResourceData, m interface{}) error {
 		}
 	}
 
-	relays, err := m.(*mullvadapi.Client).ListRelays(kind)
-	if err != nil {
+	relays, err := m.(*providerMeta).Client.ListRelays(kind)
+	if relays == nil {
 		return err
 	}
 
@@ -192,5 +191,5 @@ func dataSourceMullvadRelayRead(d *schema.ResourceData, m interface{}) error {
 
 	d.SetId(filters.(*schema.Set).GoString())
 	d.Set("relays", matching)
-	return nil
+	return err
 }
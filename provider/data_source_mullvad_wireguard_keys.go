@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"github.com/OJFord/terraform-provider-mullvad/mullvadapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"strings"
+	"time"
+)
+
+func dataSourceMullvadWireguardKeys() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"can_add_ports": {
+			Description: "Whether another port may be forwarded for the peer.",
+			Computed:    true,
+			Type:        schema.TypeBool,
+		},
+	}
+	for k, v := range wireguardPeerSchema {
+		s[k] = v
+	}
+
+	return &schema.Resource{
+		Description: "List of the WireGuard keys registered on the Mullvad account, optionally filtered.",
+
+		Read: dataSourceMullvadWireguardKeysRead,
+		Schema: map[string]*schema.Schema{
+			"assignment": assignmentFilterSchema("Whether to list only keys with ports forwarded, or only those without."),
+			"city_code": {
+				Description: "If set, only keys with a port forwarded in this city are listed.",
+				Optional:    true,
+				Type:        schema.TypeString,
+			},
+			"country_code": {
+				Description: "If set, only keys with a port forwarded in this country are listed.",
+				Optional:    true,
+				Type:        schema.TypeString,
+			},
+			"keys": {
+				Description: "List of the keys.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: s,
+				},
+				Type: schema.TypeList,
+			},
+			"max_ports": {
+				Description: "Maximum number of ports which may be forwarded on the account.",
+				Computed:    true,
+				Type:        schema.TypeInt,
+			},
+			"newer_than_days": {
+				Description: "If set, only keys registered less than this many days ago are listed.",
+				Optional:    true,
+				Type:        schema.TypeInt,
+			},
+			"older_than_days": {
+				Description: "If set, only keys registered more than this many days ago are listed.",
+				Optional:    true,
+				Type:        schema.TypeInt,
+			},
+			"unassigned_ports": {
+				Description: "Number of ports forwarded on the account which are not assigned to a key.",
+				Computed:    true,
+				Type:        schema.TypeInt,
+			},
+		},
+	}
+}
+
+// keysWithPortsIn lists the public keys to which a port in the given location
+// is assigned, where an empty code matches any.
+func keysWithPortsIn(client *mullvadapi.Client, country_code string, city_code string) (map[string]bool, error) {
+	port_forwards, err := client.ListForwardingPorts()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool)
+	for _, port_forward := range *port_forwards {
+		port_country_code, port_city_code, _ := strings.Cut(port_forward.CountryCityCode, "-")
+		if (country_code != "" && port_country_code != country_code) || (city_code != "" && port_city_code != city_code) {
+			continue
+		}
+
+		keys[port_forward.PublicKey] = true
+	}
+
+	return keys, nil
+}
+
+func dataSourceMullvadWireguardKeysRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*providerMeta).Client
+	key_list, err := client.ListWireGuardKeys()
+	if err != nil {
+		return err
+	}
+
+	assignment := d.Get("assignment").(string)
+	city_code := d.Get("city_code").(string)
+	country_code := d.Get("country_code").(string)
+	newer_than_days := d.Get("newer_than_days").(int)
+	older_than_days := d.Get("older_than_days").(int)
+
+	var located map[string]bool
+	if country_code != "" || city_code != "" {
+		if located, err = keysWithPortsIn(client, country_code, city_code); err != nil {
+			return err
+		}
+	}
+
+	keys := make([]map[string]interface{}, 0, len(key_list.Keys))
+	for _, key := range key_list.Keys {
+		if located != nil && !located[key.KeyPair.PublicKey] {
+			continue
+		}
+
+		if !matchesAssignment(assignment, len(key.Ports) > 0) {
+			continue
+		}
+
+		if older_than_days > 0 || newer_than_days > 0 {
+			created, err := parseKeyCreated(key.Created)
+			if err != nil {
+				return err
+			}
+
+			age := time.Since(created)
+			if older_than_days > 0 && age <= time.Duration(older_than_days)*24*time.Hour {
+				continue
+			}
+
+			if newer_than_days > 0 && age >= time.Duration(newer_than_days)*24*time.Hour {
+				continue
+			}
+		}
+
+		keys = append(keys, map[string]interface{}{
+			"can_add_ports":     key.CanAddPorts,
+			"created":           key.Created,
+			"ipv4_address":      key.IpV4Address,
+			"ipv6_address":      key.IpV6Address,
+			"is_app_registered": key.WasAppRegistered,
+			"ports":             key.Ports,
+			"public_key":        key.KeyPair.PublicKey,
+		})
+	}
+
+	d.SetId("wireguard_keys")
+	d.Set("keys", keys)
+	d.Set("max_ports", key_list.MaxPorts)
+	d.Set("unassigned_ports", key_list.UnassignedPorts)
+	return nil
+}
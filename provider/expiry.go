@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/OJFord/terraform-provider-mullvad/mullvadapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"math"
+	"time"
+)
+
+// The API gives both, but the Unix timestamp is unambiguous.
+func accountExpiry(acc *mullvadapi.Account) (time.Time, error) {
+	if acc.ExpiryUnix != 0 {
+		return time.Unix(int64(acc.ExpiryUnix), 0), nil
+	}
+
+	return time.Parse(time.RFC3339, acc.ExpiryDate)
+}
+
+// Whole days, and none once expired.
+func daysRemaining(expiry time.Time) int {
+	return int(math.Max(0, math.Floor(time.Until(expiry).Hours()/24)))
+}
+
+// expiryChecked is whether the provider is configured to check the account's
+// expiry at all.
+func expiryChecked(meta *providerMeta) bool {
+	return meta.failIfExpired || meta.expiryWarningDays > 0
+}
+
+func expiryDiagnostics(meta *providerMeta, expiry time.Time) diag.Diagnostics {
+	if time.Now().After(expiry) && meta.failIfExpired {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Mullvad account has expired",
+			Detail:   fmt.Sprintf("The account expired at %s. Add time to it, or unset `fail_if_expired` on the provider.", expiry.Format(time.RFC3339)),
+		}}
+	}
+
+	if meta.expiryWarningDays > 0 && time.Until(expiry) < time.Duration(meta.expiryWarningDays)*24*time.Hour {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "Mullvad account expires soon",
+			Detail:   fmt.Sprintf("The account expires at %s (%d days remaining), after which its tunnels will stop working.", expiry.Format(time.RFC3339), daysRemaining(expiry)),
+		}}
+	}
+
+	return nil
+}
+
+// unknownExpiryError is only an error if the expiry is to be checked.
+func unknownExpiryError(expires_at string) error {
+	return errors.New(fmt.Sprintf("Cannot check Mullvad account's unknown expiry '%s' - unset `warn_if_expires_within_days` and `fail_if_expired` on the provider to skip the check", expires_at))
+}
+
+// checkExpiry adapts an account read function, such that the provider's
+// expiry checks are reported once it has populated `expires_unix`.
+func checkExpiry(read schema.ReadFunc) schema.ReadContextFunc {
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		if err := read(d, m); err != nil {
+			return diag.FromErr(err)
+		}
+
+		meta := m.(*providerMeta)
+		if !expiryChecked(meta) {
+			return nil
+		}
+
+		expires_unix := d.Get("expires_unix").(int)
+		if expires_unix == 0 {
+			return diag.FromErr(unknownExpiryError(d.Get("expires_at").(string)))
+		}
+
+		return expiryDiagnostics(meta, time.Unix(int64(expires_unix), 0))
+	}
+}
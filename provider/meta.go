@@ -0,0 +1,25 @@
+package provider
+
+import (
+	"github.com/OJFord/terraform-provider-mullvad/mullvadapi"
+)
+
+// providerMeta is the configured provider: its API client, along with the
+// provider's own settings and plan state, which are of no concern to the API.
+type providerMeta struct {
+	*mullvadapi.Client
+
+	// Warn of the account expiring within this many days, if positive.
+	expiryWarningDays int
+	// Fail reading the account once it has expired.
+	failIfExpired bool
+
+	quota *quotaPlan
+}
+
+func newProviderMeta(client *mullvadapi.Client) *providerMeta {
+	return &providerMeta{
+		Client: client,
+		quota:  newQuotaPlan(),
+	}
+}
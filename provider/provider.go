@@ -1,35 +1,209 @@
 package provider
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/OJFord/terraform-provider-mullvad/mullvadapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"strings"
+	"log"
+	"os"
 )
 
 func Provider() *schema.Provider {
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"account_id": {
-				Description: "Secret account ID used to authenticate with the API. (Required if `mullvad_account` resource is not used.)",
+				Description:   "Secret account ID used to authenticate with the API. (Required, or one of `account_id_file`, `account_id_command`, or the `MULLVAD_ACCOUNT_ID` environment variable, if `mullvad_account` resource is not used.)",
+				ConflictsWith: []string{"account_id_file", "account_id_command"},
+				Optional:      true,
+				Sensitive:     true,
+				Type:          schema.TypeString,
+			},
+			"account_id_command": {
+				Description:   "Command (run by the shell) whose output is the secret account ID, e.g. a credential helper such as `pass show mullvad`.",
+				ConflictsWith: []string{"account_id", "account_id_file"},
+				Optional:      true,
+				Type:          schema.TypeString,
+			},
+			"account_id_file": {
+				Description:   "Path to a file containing the secret account ID.",
+				ConflictsWith: []string{"account_id", "account_id_command"},
+				Optional:      true,
+				Type:          schema.TypeString,
+			},
+			"allow_revoke_app_keys": {
+				Description: "Whether to allow revoking (or rotating) WireGuard keys registered by the Mullvad app, which may be in use. By default this is refused with an error.",
+				Optional:    true,
+				Type:        schema.TypeBool,
+			},
+			"audit_hash_key": {
+				Description: "Secret key with which to HMAC the account number in audit records. By default, a random key is generated once per installation, and kept in the user's config directory (e.g. `~/.config/terraform-provider-mullvad/audit_key`); set this to correlate records made elsewhere, e.g. in CI.",
 				Optional:    true,
 				Sensitive:   true,
 				Type:        schema.TypeString,
 			},
+			"audit_log_path": {
+				Description: "File to which to append a JSON Lines record of each change made to the account (and of account creation). Records include the time, operation, HMAC-SHA256 of the account number (see `audit_hash_key`), key or port affected, and outcome; as well as the `TFC_RUN_ID` environment variable if set, or otherwise an ID unique to the provider instance.",
+				Optional:    true,
+				Type:        schema.TypeString,
+			},
+			"cache_dir": {
+				Description: "Directory in which to persist the relay and city lists between runs.",
+				Optional:    true,
+				Type:        schema.TypeString,
+			},
+			"fail_if_expired": {
+				Description: "Fail (with an error) rather than continue once the account has expired, when the provider logs in and when the account is read.",
+				Optional:    true,
+				Type:        schema.TypeBool,
+			},
+			"read_only": {
+				Description: "Refuse to make any change to the account (or create one), failing instead, while reads continue to work - e.g. for drift detection with production credentials.",
+				Optional:    true,
+				Type:        schema.TypeBool,
+			},
+			"offline": {
+				Description: "If the Mullvad API cannot be reached, read relays and cities from `cache_dir` (or, failing that, a snapshot built into released versions of the provider) with a warning, instead of failing.",
+				Optional:    true,
+				Type:        schema.TypeBool,
+			},
+			"warn_if_expires_within_days": {
+				Description: "Warn when the provider logs in or the account is read, if it expires within this many days (or already has).",
+				Optional:    true,
+				Type:        schema.TypeInt,
+				ValidateFunc: func(v interface{}, k string) ([]string, []error) {
+					if v.(int) < 0 {
+						return nil, []error{errors.New(fmt.Sprintf("%s must not be negative", k))}
+					}
+
+					return nil, nil
+				},
+			},
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"mullvad_account": dataSourceMullvadAccount(),
-			"mullvad_city":    dataSourceMullvadCity(),
-			"mullvad_relay":   dataSourceMullvadRelay(),
+			"mullvad_account":        dataSourceMullvadAccount(),
+			"mullvad_cities":         dataSourceMullvadCities(),
+			"mullvad_city":           dataSourceMullvadCity(),
+			"mullvad_countries":      dataSourceMullvadCountries(),
+			"mullvad_devices":        dataSourceMullvadDevices(),
+			"mullvad_port_forwards":  dataSourceMullvadPortForwards(),
+			"mullvad_relay":          dataSourceMullvadRelay(),
+			"mullvad_wireguard_keys": dataSourceMullvadWireguardKeys(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"mullvad_account":      resourceMullvadAccount(),
-			"mullvad_port_forward": resourceMullvadPortForward(),
-			"mullvad_wireguard":    resourceMullvadWireguard(),
+			"mullvad_account":            resourceMullvadAccount(),
+			"mullvad_device":             resourceMullvadDevice(),
+			"mullvad_port_forward":       resourceMullvadPortForward(),
+			"mullvad_voucher_redemption": resourceMullvadVoucherRedemption(),
+			"mullvad_wireguard":          resourceMullvadWireguard(),
+			"mullvad_wireguard_keypair":  resourceMullvadWireguardKeypair(),
+			"mullvad_wireguard_prune":    resourceMullvadWireguardPrune(),
 		},
-		ConfigureFunc: providerConfigure,
+		ConfigureContextFunc: providerConfigure,
 	}
 }
 
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
-	return mullvadapi.GetClient(strings.Replace(d.Get("account_id").(string), " ", "", -1))
+func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	client, err := mullvadapi.GetClient("")
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	client.Offline = d.Get("offline").(bool)
+	client.AllowRevokeAppKeys = d.Get("allow_revoke_app_keys").(bool)
+	client.ReadOnly = d.Get("read_only").(bool)
+
+	meta := newProviderMeta(client)
+	meta.expiryWarningDays = d.Get("warn_if_expires_within_days").(int)
+	meta.failIfExpired = d.Get("fail_if_expired").(bool)
+
+	if run_id := os.Getenv("TFC_RUN_ID"); run_id != "" {
+		client.RunID = run_id
+	}
+
+	if audit_log_path := d.Get("audit_log_path").(string); audit_log_path != "" {
+		audit_hash_key := d.Get("audit_hash_key").(string)
+		if audit_hash_key == "" {
+			if audit_hash_key, err = installationAuditKey(); err != nil {
+				return nil, diag.FromErr(errors.New(fmt.Sprintf("Failed to read or create audit key, set `audit_hash_key` instead: %s", err)))
+			}
+		}
+
+		client.SetAuditKey(audit_hash_key)
+		client.AddAuditSink(mullvadapi.NewFileAuditSink(audit_log_path))
+	}
+
+	if cache_dir := d.Get("cache_dir").(string); cache_dir != "" {
+		if err := client.SetCacheDir(cache_dir); err != nil {
+			return nil, diag.FromErr(err)
+		}
+	}
+
+	account_id, source, err := getAccountId(ctx, d)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	if account_id != "" {
+		acc, err := client.Login(account_id)
+		if err != nil {
+			err = errors.New(fmt.Sprintf("%s (account ID from %s)", err, source))
+			if !client.Offline {
+				return nil, diag.FromErr(err)
+			}
+
+			return meta, diag.Diagnostics{{
+				Severity: diag.Warning,
+				Summary:  "Failed to login to Mullvad API",
+				Detail:   "Only relays and cities can be read offline: " + err.Error(),
+			}}
+		}
+
+		if expiryChecked(meta) {
+			expiry, err := accountExpiry(acc)
+			if err != nil {
+				log.Printf("[ERROR] %s", err)
+				return nil, diag.FromErr(unknownExpiryError(acc.ExpiryDate))
+			}
+
+			if diags := expiryDiagnostics(meta, expiry); diags.HasError() {
+				return nil, diags
+			} else if diags != nil {
+				return meta, diags
+			}
+		}
+	}
+
+	return meta, nil
+}
+
+// withAppKeyHint explains how to override the protection of app keys.
+func withAppKeyHint(err error) error {
+	var app_key *mullvadapi.AppKeyError
+	if errors.As(err, &app_key) {
+		return errors.New(fmt.Sprintf("%s. Set `allow_revoke_app_keys = true` on the provider to allow it.", err))
+	}
+
+	return err
+}
+
+// warnIfStale adapts a read function, such that a StaleResponseError returned
+// once it has (successfully) populated the data is reported as a warning.
+func warnIfStale(read schema.ReadFunc) schema.ReadContextFunc {
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		err := read(d, m)
+
+		var stale *mullvadapi.StaleResponseError
+		if errors.As(err, &stale) {
+			return diag.Diagnostics{{
+				Severity: diag.Warning,
+				Summary:  "Mullvad API unreachable, using stale data",
+				Detail:   stale.Error(),
+			}}
+		}
+
+		return diag.FromErr(err)
+	}
 }
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"sync"
+)
+
+func countOf(n int, thing string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, thing)
+	}
+
+	return fmt.Sprintf("%d %ss", n, thing)
+}
+
+// quotaKind is something of which an account may only have a limited number.
+type quotaKind string
+
+const (
+	quotaKeys  quotaKind = "key"
+	quotaPorts quotaKind = "port"
+)
+
+// quotaPlan counts the keys and ports planned to be created, which once
+// created are instead counted in the account's usage.
+type quotaPlan struct {
+	// Held (shared) while creating, and exclusively while checking, so that
+	// nothing's ever counted both as planned and as used.
+	creating sync.RWMutex
+
+	mu      sync.Mutex
+	planned map[quotaKind]int
+}
+
+func newQuotaPlan() *quotaPlan {
+	return &quotaPlan{
+		planned: make(map[quotaKind]int),
+	}
+}
+
+// plan counts another creation of kind planned, returning the number (yet to
+// be created) now planned.
+func (q *quotaPlan) plan(kind quotaKind) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.planned[kind]++
+	return q.planned[kind]
+}
+
+// create one of kind, after which it's no longer counted as planned - whether
+// it's then used, or failed.
+func (q *quotaPlan) create(kind quotaKind, create func() error) error {
+	q.creating.RLock()
+	defer q.creating.RUnlock()
+
+	err := create()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.planned[kind] > 0 {
+		q.planned[kind]--
+	}
+
+	return err
+}
+
+// checkQuota counts a resource planned for creation (per provider instance),
+// and checks the total planned against the account's limit, so that it fails
+// at plan time rather than part-way through apply.
+func checkQuota(meta *providerMeta, kind quotaKind) error {
+	if !meta.IsLoggedIn() {
+		// The account is not yet known, e.g. it's a `mullvad_account` that
+		// has yet to be created.
+		return nil
+	}
+
+	meta.quota.creating.Lock()
+	defer meta.quota.creating.Unlock()
+
+	acc, err := meta.GetAccount()
+	if err != nil {
+		return err
+	}
+
+	var limit, used int
+	switch kind {
+	case quotaKeys:
+		limit, used = acc.MaxWireGuardPeers, len(acc.WireGuardPeers)
+	case quotaPorts:
+		limit, used = acc.MaxForwardingPorts, len(acc.ForwardingPorts)
+	}
+
+	planned := meta.quota.plan(kind)
+	if limit == 0 {
+		// Limit not reported
+		return nil
+	}
+
+	if remaining := max(limit-used, 0); planned > remaining {
+		remain := "remain"
+		if remaining == 1 {
+			remain = "remains"
+		}
+
+		return errors.New(fmt.Sprintf("plan adds %s but only %s %s (of %d)", countOf(planned, string(kind)), countOf(remaining, "slot"), remain, limit))
+	}
+
+	return nil
+}
+
+// isCreate is whether the diff is of a resource to be created, rather than
+// the second pass over one to be replaced - which frees its slot before (by
+// default) taking another.
+func isCreate(d *schema.ResourceDiff) bool {
+	return d.Id() == "" && d.GetRawState().IsNull()
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"errors"
+	"github.com/OJFord/terraform-provider-mullvad/mullvadapi"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckQuota(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		account := `{"token": "1234567890123456", "max_wg_peers": 2, "wg_peers": [{}], "max_ports": 1, "city_ports": [{}]}`
+		switch r.URL.Path {
+		case "/www/accounts/1234567890123456/":
+			w.Write([]byte(`{"auth_token": "token", "account": ` + account + `}`))
+		case "/www/me/":
+			w.Write([]byte(`{"account": ` + account + `}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := mullvadapi.GetClient("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetDebug(false)
+	client.SetHostURL(server.URL)
+	meta := newProviderMeta(client)
+
+	if err := checkQuota(meta, quotaKeys); err != nil {
+		t.Fatalf("Expected no check before login, got %s", err)
+	}
+
+	if _, err := client.Login("1234567890123456"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checkQuota(meta, quotaKeys); err != nil {
+		t.Fatalf("Expected the first key to fit, got %s", err)
+	}
+
+	expected := map[quotaKind]string{
+		quotaKeys:  "plan adds 2 keys but only 1 slot remains (of 2)",
+		quotaPorts: "plan adds 1 port but only 0 slots remain (of 1)",
+	}
+	for kind, message := range expected {
+		if err := checkQuota(meta, kind); err == nil || err.Error() != message {
+			t.Errorf("Expected %q, got %v", message, err)
+		}
+	}
+}
+
+func TestCreationStopsCountingAsPlanned(t *testing.T) {
+	quota := newQuotaPlan()
+
+	quota.plan(quotaKeys)
+	if planned := quota.plan(quotaKeys); planned != 2 {
+		t.Fatalf("Expected 2 planned, got %d", planned)
+	}
+
+	if err := quota.create(quotaKeys, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if planned := quota.plan(quotaKeys); planned != 2 {
+		t.Errorf("Expected the created key no longer to be planned, got %d planned", planned)
+	}
+
+	if err := quota.create(quotaKeys, func() error { return errors.New("Failed") }); err == nil {
+		t.Error("Expected the creation's error")
+	}
+
+	if planned := quota.plan(quotaKeys); planned != 2 {
+		t.Errorf("Expected the failed key no longer to be planned, got %d planned", planned)
+	}
+
+	if planned := quota.plan(quotaPorts); planned != 1 {
+		t.Errorf("Expected ports to be counted separately, got %d", planned)
+	}
+}
@@ -1,54 +1,248 @@
 package provider
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/OJFord/terraform-provider-mullvad/mullvadapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"log"
+	"strings"
+	"time"
 )
 
+const accountNumberLength = 16
+
+// compactAccountNumber removes the spaces of the 'pretty' form.
+func compactAccountNumber(account_number string) string {
+	return strings.Join(strings.Fields(account_number), "")
+}
+
+// normaliseAccountNumber accepts the 'pretty' form too, e.g. `1234 5678 ...`.
+func normaliseAccountNumber(account_number string) (string, error) {
+	normalised := compactAccountNumber(account_number)
+
+	if len(normalised) != accountNumberLength {
+		return "", errors.New(fmt.Sprintf("Mullvad account number should have %d digits, not %d", accountNumberLength, len(normalised)))
+	}
+
+	for _, c := range normalised {
+		if c < '0' || c > '9' {
+			return "", errors.New("Mullvad account number should consist only of digits (and spaces)")
+		}
+	}
+
+	return normalised, nil
+}
+
 func resourceMullvadAccount() *schema.Resource {
+	s := map[string]*schema.Schema{}
+	for k, v := range accountSchema {
+		s[k] = v
+	}
+
+	s["account_number"] = &schema.Schema{
+		Description: "The (secret) number of an existing account to adopt, rather than creating a new one. May be given in the 'pretty' form, with spaces. Otherwise, that of the created account.",
+		Computed:    true,
+		ForceNew:    true,
+		Optional:    true,
+		Sensitive:   true,
+		Type:        schema.TypeString,
+		DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+			return compactAccountNumber(old) == compactAccountNumber(new)
+		},
+		ValidateFunc: func(v interface{}, k string) ([]string, []error) {
+			if _, err := normaliseAccountNumber(v.(string)); err != nil {
+				// Not including the value, which is secret.
+				return nil, []error{errors.New(fmt.Sprintf("%s: %s", k, err))}
+			}
+
+			return nil, nil
+		},
+	}
+
+	s["delete_on_destroy"] = &schema.Schema{
+		Description: "Whether to actually delete the account (after revoking its WireGuard keys and forwarding ports) when the resource is destroyed, rather than just forgetting it. Must be applied before the destroy to take effect.",
+		Default:     false,
+		Optional:    true,
+		Type:        schema.TypeBool,
+	}
+
+	s["force_delete_with_remaining_time"] = &schema.Schema{
+		Description: "Whether to delete the account (with `delete_on_destroy`) even though it has paid time remaining, which will be lost.",
+		Default:     false,
+		Optional:    true,
+		Type:        schema.TypeBool,
+	}
+
 	return &schema.Resource{
-		Description: "Provides access to a Mullvad account. Required if the provider is not configured with an `account_id`.",
-		Schema:      accountSchema,
+		Description: "Provides access to a Mullvad account, either creating a new one or adopting an existing `account_number`. Required if the provider is not configured with an `account_id`.",
+		Schema:      s,
 
 		Importer: &schema.ResourceImporter{
 			State: importAccount,
 		},
 
-		Create: resourceMullvadAccountCreate,
-		Read:   resourceMullvadAccountRead,
-		Delete: resourceMullvadAccountDelete,
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceMullvadAccountV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceMullvadAccountUpgradeV0,
+			},
+		},
+
+		Create:      resourceMullvadAccountCreate,
+		ReadContext: checkExpiry(resourceMullvadAccountRead),
+		Update:      resourceMullvadAccountUpdate,
+		Delete:      resourceMullvadAccountDelete,
+	}
+}
+
+func resourceMullvadAccountV0() *schema.Resource {
+	s := map[string]*schema.Schema{}
+	for _, k := range []string{"account_number", "expires_at", "subscription_method"} {
+		s[k] = &schema.Schema{Optional: true, Type: schema.TypeString}
+	}
+	for _, k := range []string{"delete_on_destroy", "force_delete_with_remaining_time", "is_active", "is_subscription_unpaid"} {
+		s[k] = &schema.Schema{Optional: true, Type: schema.TypeBool}
+	}
+	for _, k := range []string{"max_forwarding_ports", "max_wireguard_peers"} {
+		s[k] = &schema.Schema{Optional: true, Type: schema.TypeInt}
+	}
+
+	return &schema.Resource{
+		Schema: s,
+	}
+}
+
+// Version 0 used the (secret) account number as the ID.
+func resourceMullvadAccountUpgradeV0(ctx context.Context, rawState map[string]interface{}, m interface{}) (map[string]interface{}, error) {
+	if account_number, ok := rawState["account_number"].(string); !ok || account_number == "" {
+		rawState["account_number"] = rawState["id"]
+	}
+
+	client := m.(*providerMeta).Client
+	if _, err := client.Login(compactAccountNumber(fmt.Sprint(rawState["account_number"]))); err != nil {
+		return nil, err
 	}
+
+	id, err := client.GetAccountID()
+	if err != nil {
+		return nil, err
+	}
+
+	rawState["id"] = id
+	return rawState, nil
 }
 
+// The import ID is the (secret) account number, which is replaced by its UUID.
 func importAccount(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	account_number, err := normaliseAccountNumber(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	d.Set("account_number", account_number)
+	if err := resourceMullvadAccountRead(d, m); err != nil {
+		return nil, err
+	}
+
 	return []*schema.ResourceData{d}, nil
 }
 
 func resourceMullvadAccountCreate(d *schema.ResourceData, m interface{}) error {
-	acc, err := m.(*mullvadapi.Client).CreateAccount()
+	client := m.(*providerMeta).Client
+
+	if account_number, ok := d.GetOk("account_number"); ok {
+		normalised, err := normaliseAccountNumber(account_number.(string))
+		if err != nil {
+			return err
+		}
+
+		acc, err := client.Login(normalised)
+		if err != nil {
+			return err
+		}
+
+		if err := populateAccountResource(d, client, acc); err != nil {
+			return err
+		}
+
+		log.Printf("Adopted %s", d.Id())
+		return nil
+	}
+
+	acc, err := client.CreateAccount()
 	if err != nil {
 		return err
 	}
 
-	log.Printf("Created %s", acc.Token)
-	populateAccountResource(d, acc)
+	if err := populateAccountResource(d, client, acc); err != nil {
+		return err
+	}
+
 	log.Printf("Created %s", d.Id())
 	return nil
 }
 
 func resourceMullvadAccountRead(d *schema.ResourceData, m interface{}) error {
-	acc, err := m.(*mullvadapi.Client).Login(d.Id())
+	client := m.(*providerMeta).Client
+	acc, err := client.Login(compactAccountNumber(d.Get("account_number").(string)))
 	if err != nil {
 		return err
 	}
 
 	log.Printf("Reading %s", d.Id())
-	populateAccountResource(d, acc)
-	return nil
+	return populateAccountResource(d, client, acc)
+}
+
+func resourceMullvadAccountUpdate(d *schema.ResourceData, m interface{}) error {
+	// Only the deletion options may change, which need nothing but saving.
+	return resourceMullvadAccountRead(d, m)
 }
 
 func resourceMullvadAccountDelete(d *schema.ResourceData, m interface{}) error {
-	// I don't think there's a way to delete, so just NOP & forget.
-	return nil
+	if !d.Get("delete_on_destroy").(bool) {
+		log.Printf("[INFO] Forgetting account, without `delete_on_destroy`")
+		return nil
+	}
+
+	client := m.(*providerMeta).Client
+	acc, err := client.Login(compactAccountNumber(d.Get("account_number").(string)))
+	if err != nil {
+		return err
+	}
+
+	if !d.Get("force_delete_with_remaining_time").(bool) {
+		if expiry, err := accountExpiry(acc); err != nil {
+			return errors.New(fmt.Sprintf("Refusing to delete account with unknown expiry '%s' - set `force_delete_with_remaining_time = true` to delete it anyway", acc.ExpiryDate))
+		} else if time.Now().Before(expiry) {
+			return errors.New(fmt.Sprintf("Refusing to delete account with paid time remaining until %s - set `force_delete_with_remaining_time = true` to delete it anyway", acc.ExpiryDate))
+		}
+	}
+
+	// Before removing anything, rather than leave the account part torn down.
+	if !client.AllowRevokeAppKeys {
+		for _, peer := range acc.WireGuardPeers {
+			if peer.WasAppRegistered {
+				return withAppKeyHint(&mullvadapi.AppKeyError{Key: peer.KeyResponse, Operation: "delete account with"})
+			}
+		}
+	}
+
+	for _, port := range acc.ForwardingPorts {
+		country_code, city_code, _ := strings.Cut(port.CountryCityCode, "-")
+		if err := client.RemoveForwardingPort(country_code, city_code, port.Port); err != nil {
+			return err
+		}
+	}
+
+	for _, peer := range acc.WireGuardPeers {
+		if err := client.RevokeWireGuardKey(peer.KeyPair.PublicKey); err != nil {
+			return withAppKeyHint(err)
+		}
+	}
+
+	return client.DeleteAccount()
 }
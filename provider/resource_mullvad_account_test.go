@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"context"
+	"github.com/OJFord/terraform-provider-mullvad/mullvadapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newTestMeta(t *testing.T, handler http.Handler) *providerMeta {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := mullvadapi.GetClient("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.SetDebug(false)
+	client.SetHostURL(server.URL)
+	return newProviderMeta(client)
+}
+
+func TestNormaliseAccountNumber(t *testing.T) {
+	for _, account_number := range []string{"1234567890123456", "1234 5678 9012 3456", " 1234  5678 9012 3456\n"} {
+		normalised, err := normaliseAccountNumber(account_number)
+		if err != nil {
+			t.Errorf("Expected '%s' to be valid: %s", account_number, err)
+		} else if normalised != "1234567890123456" {
+			t.Errorf("Expected '%s' to normalise to 1234567890123456, got %s", account_number, normalised)
+		}
+	}
+}
+
+func TestNormaliseAccountNumberInvalid(t *testing.T) {
+	for _, account_number := range []string{"", "123456789012345", "12345678901234567", "1234-5678-9012-3456", "123456789012345a"} {
+		if _, err := normaliseAccountNumber(account_number); err == nil {
+			t.Errorf("Expected '%s' to be invalid", account_number)
+		}
+	}
+}
+
+func TestCompactAccountNumber(t *testing.T) {
+	if compacted := compactAccountNumber("1234 5678 9012 3456"); compacted != "1234567890123456" {
+		t.Errorf("Expected 1234567890123456, got %s", compacted)
+	}
+}
+
+func TestAccountDeleteRefusesAppKeysFirst(t *testing.T) {
+	var mu sync.Mutex
+	var modifications []string
+	meta := newTestMeta(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			mu.Lock()
+			modifications = append(modifications, r.Method+" "+r.URL.Path)
+			mu.Unlock()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/www/accounts/1234567890123456/":
+			w.Write([]byte(`{"auth_token": "token", "account": {
+				"token": "1234567890123456",
+				"expiry_unix": 946684800,
+				"city_ports": [{"port": 54321, "city_code": "gb-lon"}],
+				"wg_peers": [{"key": {"public": "own"}}, {"key": {"public": "app"}, "app": true}]
+			}}`))
+		case "/www/wg-pubkeys/list/":
+			w.Write([]byte(`{"keys": [{"key": {"public": "own"}}, {"key": {"public": "app"}, "app": true}]}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+
+	d := schema.TestResourceDataRaw(t, resourceMullvadAccount().Schema, map[string]interface{}{
+		"account_number":    "1234567890123456",
+		"delete_on_destroy": true,
+	})
+
+	err := resourceMullvadAccountDelete(d, meta)
+	if err == nil || !strings.Contains(err.Error(), "key app registered by the Mullvad app") {
+		t.Fatalf("Expected the app key to be refused, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(modifications) != 0 {
+		t.Errorf("Expected nothing to be removed, got %v", modifications)
+	}
+}
+
+func TestAccountUpgradeV0(t *testing.T) {
+	meta := newTestMeta(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/www/accounts/1234567890123456/":
+			w.Write([]byte(`{"auth_token": "token", "account": {"token": "1234567890123456"}}`))
+		case "/auth/v1/token":
+			w.Write([]byte(`{"access_token": "token", "expiry": "2030-01-01T00:00:00Z"}`))
+		case "/accounts/v1/accounts/me":
+			w.Write([]byte(`{"id": "f0e1d2c3-b4a5-9687-7869-5a4b3c2d1e0f", "number": "1234567890123456"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	state, err := resourceMullvadAccountUpgradeV0(context.Background(), map[string]interface{}{
+		"id":                "1234567890123456",
+		"delete_on_destroy": false,
+		"expires_at":        "2030-01-01T00:00:00+00:00",
+	}, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state["account_number"] != "1234567890123456" {
+		t.Errorf("Expected account_number 1234567890123456, got %v", state["account_number"])
+	}
+
+	if state["id"] != "f0e1d2c3-b4a5-9687-7869-5a4b3c2d1e0f" {
+		t.Errorf("Expected the account's UUID as ID, got %v", state["id"])
+	}
+}
@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"context"
+	"github.com/OJFord/terraform-provider-mullvad/mullvadapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var deviceSchema = map[string]*schema.Schema{
+	"created": {
+		Description: "Timestamp (RFC3339) at which the device was created.",
+		Computed:    true,
+		Type:        schema.TypeString,
+	},
+	"hijack_dns": {
+		Description: "Whether DNS requests from the device are hijacked to Mullvad's DNS servers.",
+		Computed:    true,
+		Type:        schema.TypeBool,
+	},
+	"ipv4_address": {
+		Description: "The IPv4 address the device may use (its `AllowedIPs` value to Mullvad's peers).",
+		Computed:    true,
+		Type:        schema.TypeString,
+	},
+	"ipv6_address": {
+		Description: "The IPv6 address the device may use (its `AllowedIPs` value to Mullvad's peers).",
+		Computed:    true,
+		Type:        schema.TypeString,
+	},
+	"name": {
+		Description: "The name Mullvad has given the device.",
+		Computed:    true,
+		Type:        schema.TypeString,
+	},
+	"ports": {
+		Description: "The IDs of the ports forwarded to the device.",
+		Computed:    true,
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+		},
+		Type: schema.TypeList,
+	},
+	"public_key": {
+		Description: "The device's WireGuard public key.",
+		Computed:    true,
+		Type:        schema.TypeString,
+	},
+}
+
+func resourceMullvadDevice() *schema.Resource {
+	s := map[string]*schema.Schema{}
+	for k, v := range deviceSchema {
+		s[k] = v
+	}
+
+	s["hijack_dns"] = &schema.Schema{
+		Description: "Whether to hijack DNS requests from the device to Mullvad's DNS servers.",
+		Default:     false,
+		ForceNew:    true,
+		Optional:    true,
+		Type:        schema.TypeBool,
+	}
+	s["public_key"] = &schema.Schema{
+		Description: "The WireGuard public key of the device to register. Changing it rotates the device's key in place, keeping its name and addresses.",
+		Required:    true,
+		Type:        schema.TypeString,
+	}
+
+	return &schema.Resource{
+		Description: "Provides a Mullvad device - a named WireGuard peer with its own addresses. Accounts are limited to 5 devices.",
+		Schema:      s,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		CustomizeDiff: resourceMullvadDeviceCustomizeDiff,
+
+		Create: resourceMullvadDeviceCreate,
+		Read:   resourceMullvadDeviceRead,
+		Update: resourceMullvadDeviceUpdate,
+		Delete: resourceMullvadDeviceDelete,
+	}
+}
+
+func flattenDevice(device *mullvadapi.Device) map[string]interface{} {
+	ports := make([]string, 0, len(device.Ports))
+	for _, port := range device.Ports {
+		ports = append(ports, port.ID)
+	}
+
+	return map[string]interface{}{
+		"created":      device.Created,
+		"hijack_dns":   device.HijackDNS,
+		"id":           device.ID,
+		"ipv4_address": device.IpV4Address,
+		"ipv6_address": device.IpV6Address,
+		"name":         device.Name,
+		"ports":        ports,
+		"public_key":   device.PublicKey,
+	}
+}
+
+func resourceMullvadDeviceCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if isCreate(d) {
+		// Devices are WireGuard peers, limited the same as keys.
+		return checkQuota(m.(*providerMeta), quotaKeys)
+	}
+
+	return nil
+}
+
+func resourceMullvadDeviceCreate(d *schema.ResourceData, m interface{}) error {
+	meta := m.(*providerMeta)
+
+	var device *mullvadapi.Device
+	err := meta.quota.create(quotaKeys, func() (err error) {
+		device, err = meta.AddDevice(d.Get("public_key").(string), d.Get("hijack_dns").(bool))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(device.ID)
+	return resourceMullvadDeviceRead(d, m)
+}
+
+func resourceMullvadDeviceRead(d *schema.ResourceData, m interface{}) error {
+	device, err := m.(*providerMeta).Client.GetDevice(d.Id())
+	if err != nil {
+		if err == mullvadapi.ErrDeviceNotFound {
+			d.SetId("")
+			return nil
+		}
+
+		return err
+	}
+
+	for k, v := range flattenDevice(device) {
+		if k != "id" {
+			d.Set(k, v)
+		}
+	}
+
+	return nil
+}
+
+func resourceMullvadDeviceUpdate(d *schema.ResourceData, m interface{}) error {
+	if d.HasChange("public_key") {
+		if _, err := m.(*providerMeta).Client.RotateDeviceKey(d.Id(), d.Get("public_key").(string)); err != nil {
+			return err
+		}
+	}
+
+	return resourceMullvadDeviceRead(d, m)
+}
+
+func resourceMullvadDeviceDelete(d *schema.ResourceData, m interface{}) error {
+	return m.(*providerMeta).Client.RemoveDevice(d.Id())
+}
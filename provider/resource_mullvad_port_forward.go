@@ -1,20 +1,45 @@
 package provider
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/OJFord/terraform-provider-mullvad/mullvadapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"strconv"
+	"strings"
 )
 
 func resourceMullvadPortForward() *schema.Resource {
 	return &schema.Resource{
 		Description: "Provides a Mullvad port forward resource. This can be used to create, read, update, and delete forwarding ports on your Mullvad account.",
 
+		Importer: &schema.ResourceImporter{
+			State: importPortForward,
+		},
+
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceMullvadPortForwardV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceMullvadPortForwardUpgradeV0,
+			},
+		},
+
 		Create: resourceMullvadPortForwardCreate,
 		Read:   resourceMullvadPortForwardRead,
+		Update: resourceMullvadPortForwardUpdate,
 		Delete: resourceMullvadPortForwardDelete,
 
+		CustomizeDiff: resourceMullvadPortForwardCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
+			"assigned": {
+				Description: "Whether the port is assigned to a WireGuard peer.",
+				Computed:    true,
+				Type:        schema.TypeBool,
+			},
 			"city_code": {
 				Description: "Mullvad's code for the city in which the relay to which the forwarding target will connect is located, e.g. `\"lon\"` for London.",
 				Required:    true,
@@ -28,9 +53,8 @@ func resourceMullvadPortForward() *schema.Resource {
 				Type:        schema.TypeString,
 			},
 			"peer": {
-				Description: "The public key of the WireGuard peer, if any, to assign forward this port to. (Required for WireGuard; not applicable for OpenVPN connections.",
+				Description: "The public key of the WireGuard peer, if any, to assign forward this port to. (Required for WireGuard; not applicable for OpenVPN connections.) Changing it reassigns the same port.",
 				Optional:    true,
-				ForceNew:    true,
 				Type:        schema.TypeString,
 			},
 			"port": {
@@ -43,6 +67,78 @@ func resourceMullvadPortForward() *schema.Resource {
 	}
 }
 
+// The ID is `<country_code>-<city_code>:<port>`, since ports are only unique
+// per location.
+func portForwardId(country_code string, city_code string, port int) string {
+	return fmt.Sprintf("%s-%s:%d", country_code, city_code, port)
+}
+
+func parsePortForwardId(id string) (string, string, int, error) {
+	location, port_str, _ := strings.Cut(id, ":")
+	country_code, city_code, _ := strings.Cut(location, "-")
+	port, err := strconv.Atoi(port_str)
+	if err != nil || country_code == "" || city_code == "" {
+		return "", "", 0, errors.New(fmt.Sprintf("Expected ID of the form '<country_code>-<city_code>:<port>', e.g. 'gb-lon:54321', got '%s'", id))
+	}
+
+	return country_code, city_code, port, nil
+}
+
+func resourceMullvadPortForwardV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"city_code": {
+				Required: true,
+				Type:     schema.TypeString,
+			},
+			"country_code": {
+				Required: true,
+				Type:     schema.TypeString,
+			},
+			"peer": {
+				Optional: true,
+				Type:     schema.TypeString,
+			},
+			"port": {
+				Computed: true,
+				Type:     schema.TypeInt,
+			},
+		},
+	}
+}
+
+// Version 0 used the bare port as the ID.
+func resourceMullvadPortForwardUpgradeV0(ctx context.Context, rawState map[string]interface{}, m interface{}) (map[string]interface{}, error) {
+	port, err := strconv.Atoi(fmt.Sprint(rawState["id"]))
+	if err != nil {
+		return nil, err
+	}
+
+	rawState["id"] = portForwardId(fmt.Sprint(rawState["country_code"]), fmt.Sprint(rawState["city_code"]), port)
+	return rawState, nil
+}
+
+func importPortForward(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	country_code, city_code, port, err := parsePortForwardId(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := m.(*providerMeta).Client.GetForwardingPort(country_code, city_code, port); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceMullvadPortForwardCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if isCreate(d) {
+		return checkQuota(m.(*providerMeta), quotaPorts)
+	}
+
+	return nil
+}
+
 func resourceMullvadPortForwardCreate(d *schema.ResourceData, m interface{}) error {
 	country_code := d.Get("country_code").(string)
 	city_code := d.Get("city_code").(string)
@@ -52,28 +148,37 @@ func resourceMullvadPortForwardCreate(d *schema.ResourceData, m interface{}) err
 		public_key = &pk
 	}
 
-	added_port, err := m.(*mullvadapi.Client).AddForwardingPort(country_code, city_code, public_key)
+	var added_port *int
+	err := m.(*providerMeta).quota.create(quotaPorts, func() (err error) {
+		added_port, err = m.(*providerMeta).AddForwardingPort(country_code, city_code, public_key)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
-	d.SetId(strconv.Itoa(*added_port))
+	d.SetId(portForwardId(country_code, city_code, *added_port))
 	return resourceMullvadPortForwardRead(d, m)
 }
 
 func resourceMullvadPortForwardRead(d *schema.ResourceData, m interface{}) error {
-	country_code := d.Get("country_code").(string)
-	city_code := d.Get("city_code").(string)
-	port, err := strconv.Atoi(d.Id())
+	country_code, city_code, port, err := parsePortForwardId(d.Id())
 	if err != nil {
 		return err
 	}
 
-	port_forward, err := m.(*mullvadapi.Client).GetForwardingPort(country_code, city_code, port)
+	port_forward, err := m.(*providerMeta).Client.GetForwardingPort(country_code, city_code, port)
 	if err != nil {
+		if err == mullvadapi.ErrPortNotFound {
+			d.SetId("")
+			return nil
+		}
+
 		return err
 	}
 
+	d.Set("country_code", country_code)
+	d.Set("city_code", city_code)
 	d.Set("port", port_forward.Port)
 	d.Set("assigned", port_forward.PublicKey != "")
 	d.Set("peer", port_forward.PublicKey)
@@ -81,12 +186,33 @@ func resourceMullvadPortForwardRead(d *schema.ResourceData, m interface{}) error
 	return nil
 }
 
+func resourceMullvadPortForwardUpdate(d *schema.ResourceData, m interface{}) error {
+	country_code, city_code, port, err := parsePortForwardId(d.Id())
+	if err != nil {
+		return err
+	}
+
+	if d.HasChange("peer") {
+		var public_key *string = nil
+		if pk := d.Get("peer").(string); pk != "" {
+			public_key = &pk
+		}
+
+		if err := m.(*providerMeta).Client.AssignForwardingPort(country_code, city_code, port, public_key); err != nil {
+			return err
+		}
+	}
+
+	return resourceMullvadPortForwardRead(d, m)
+}
+
 func resourceMullvadPortForwardDelete(d *schema.ResourceData, m interface{}) error {
-	country_code := d.Get("country_code").(string)
-	city_code := d.Get("city_code").(string)
-	port := d.Get("port").(int)
+	country_code, city_code, port, err := parsePortForwardId(d.Id())
+	if err != nil {
+		return err
+	}
 
-	if err := m.(*mullvadapi.Client).RemoveForwardingPort(country_code, city_code, port); err != nil {
+	if err := m.(*providerMeta).Client.RemoveForwardingPort(country_code, city_code, port); err != nil {
 		return err
 	}
 
@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParsePortForwardId(t *testing.T) {
+	country_code, city_code, port, err := parsePortForwardId(portForwardId("gb", "lon", 54321))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if country_code != "gb" || city_code != "lon" || port != 54321 {
+		t.Errorf("Expected gb, lon, 54321; got %s, %s, %d", country_code, city_code, port)
+	}
+}
+
+func TestParsePortForwardIdInvalid(t *testing.T) {
+	for _, id := range []string{"54321", "gb-lon", "gb-lon:", "gb:54321", "-lon:54321", "gb-lon:port"} {
+		if _, _, _, err := parsePortForwardId(id); err == nil {
+			t.Errorf("Expected '%s' to be invalid", id)
+		}
+	}
+}
+
+func TestPortForwardUpgradeV0(t *testing.T) {
+	state, err := resourceMullvadPortForwardUpgradeV0(context.Background(), map[string]interface{}{
+		"id":           "54321",
+		"city_code":    "lon",
+		"country_code": "gb",
+		"port":         54321,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state["id"] != "gb-lon:54321" {
+		t.Errorf("Expected ID 'gb-lon:54321', got %v", state["id"])
+	}
+}
+
+func TestPortForwardUpgradeV0Invalid(t *testing.T) {
+	if _, err := resourceMullvadPortForwardUpgradeV0(context.Background(), map[string]interface{}{"id": "gb-lon:54321"}, nil); err == nil {
+		t.Error("Expected an already upgraded ID to be invalid")
+	}
+}
@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceMullvadVoucherRedemption() *schema.Resource {
+	return &schema.Resource{
+		Description: "Redeems a voucher, adding its time to the Mullvad account. Destroying it has no effect on the account.",
+
+		Create: resourceMullvadVoucherRedemptionCreate,
+		Read:   resourceMullvadVoucherRedemptionRead,
+		Delete: resourceMullvadVoucherRedemptionDelete,
+
+		Schema: map[string]*schema.Schema{
+			"account_number": {
+				Description: "The (secret) number of the account to which to add the voucher's time, e.g. a `mullvad_account`'s `account_number`. Defaults to the account the provider is logged in to.",
+				ForceNew:    true,
+				Optional:    true,
+				Sensitive:   true,
+				Type:        schema.TypeString,
+			},
+			"new_expiry": {
+				Description: "Timestamp (RFC3339) at which the account was due to expire, once the voucher was redeemed.",
+				Computed:    true,
+				Type:        schema.TypeString,
+			},
+			"time_added": {
+				Description: "Number of seconds the voucher added to the account.",
+				Computed:    true,
+				Type:        schema.TypeInt,
+			},
+			"voucher_code": {
+				Description: "The (secret) voucher code to redeem.",
+				ForceNew:    true,
+				Required:    true,
+				Sensitive:   true,
+				Type:        schema.TypeString,
+			},
+		},
+	}
+}
+
+func resourceMullvadVoucherRedemptionCreate(d *schema.ResourceData, m interface{}) error {
+	voucher_code := d.Get("voucher_code").(string)
+
+	account_number := ""
+	if v, ok := d.GetOk("account_number"); ok {
+		normalised, err := normaliseAccountNumber(v.(string))
+		if err != nil {
+			return err
+		}
+		account_number = normalised
+	}
+
+	redemption, err := m.(*providerMeta).Client.RedeemVoucher(voucher_code, account_number)
+	if err != nil {
+		return err
+	}
+
+	// Not the code itself, which would be shown as the ID.
+	sum := sha256.Sum256([]byte(voucher_code))
+	d.SetId(hex.EncodeToString(sum[:]))
+
+	d.Set("new_expiry", redemption.NewExpiry)
+	d.Set("time_added", redemption.TimeAdded)
+	return nil
+}
+
+func resourceMullvadVoucherRedemptionRead(d *schema.ResourceData, m interface{}) error {
+	// There's nothing to read back; a redemption is only a record of the past.
+	return nil
+}
+
+func resourceMullvadVoucherRedemptionDelete(d *schema.ResourceData, m interface{}) error {
+	// Time can't be given back, so just NOP & forget.
+	return nil
+}
@@ -1,24 +1,47 @@
 package provider
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/OJFord/terraform-provider-mullvad/mullvadapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"log"
+	"time"
 )
 
 func resourceMullvadWireguard() *schema.Resource {
 	return &schema.Resource{
-		Description: "Provides a Mullvad WireGuard resource. This can be used to create, read, and delete WireGuard keys on your Mullvad account.",
+		Description: "Provides a Mullvad WireGuard resource. This can be used to create, read, rotate, and delete WireGuard keys on your Mullvad account.",
+
+		Importer: &schema.ResourceImporter{
+			State: importWireguard,
+		},
 
 		Create: resourceMullvadWireguardCreate,
 		Read:   resourceMullvadWireguardRead,
+		Update: resourceMullvadWireguardUpdate,
 		Delete: resourceMullvadWireguardDelete,
 
+		CustomizeDiff: resourceMullvadWireguardCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
+			"adopt_existing": {
+				Description: "Whether to adopt the key if it is already registered (e.g. by the Mullvad app, or by a previous attempt whose response was lost), rather than fail to create it.",
+				Default:     false,
+				Optional:    true,
+				Type:        schema.TypeBool,
+			},
 			"created": {
 				Description: "The date the peer was registered.",
 				Computed:    true,
 				Type:        schema.TypeString,
 			},
+			"expires_at": {
+				Description: "Timestamp (RFC3339) after which the key is due to be rotated, if `rotation_days` is set - e.g. for alerting.",
+				Computed:    true,
+				Type:        schema.TypeString,
+			},
 			"ipv4_address": {
 				Description: "The IPv4 address the registered peer may use (its `AllowedIPs` value to Mullvad's peers).",
 				Computed:    true,
@@ -38,21 +61,69 @@ func resourceMullvadWireguard() *schema.Resource {
 				Type: schema.TypeList,
 			},
 			"public_key": {
-				Description: "The public key of the WireGuard peer to register.",
-				ForceNew:    true,
+				Description: "The public key of the WireGuard peer to register. Changing it rotates the key in place (see `rotate_in_place`).",
 				Required:    true,
 				Type:        schema.TypeString,
 			},
+			"rotation_days": {
+				Description: "Number of days after registration after which the key is due to be rotated, which only sets `expires_at`. This doesn't itself rotate the key, nor plan anything once it's due: provide `public_key` from a `mullvad_wireguard_keypair` with `rotation_days`, and it's rotated in place.",
+				Optional:    true,
+				Type:        schema.TypeInt,
+			},
+			"rotate_in_place": {
+				Description: "Whether to rotate to a new `public_key` in place, keeping the addresses and forwarded ports of the old; planning fails if the old key is no longer registered. Otherwise, the plan shows the key being replaced - revoked and a new one registered, with new addresses and no ports.",
+				Default:     true,
+				Optional:    true,
+				Type:        schema.TypeBool,
+			},
 		},
 	}
 }
 
+func importWireguard(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	if _, err := m.(*providerMeta).Client.GetWireGuardKey(d.Id()); err != nil {
+		if err == mullvadapi.ErrKeyNotFound {
+			return nil, errors.New(fmt.Sprintf("No registered key '%s'", d.Id()))
+		}
+
+		return nil, err
+	}
+
+	d.Set("public_key", d.Id())
+	// Otherwise the first plan would show setting them.
+	d.Set("adopt_existing", false)
+	d.Set("rotate_in_place", true)
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceMullvadWireguardCreate(d *schema.ResourceData, m interface{}) error {
+	meta := m.(*providerMeta)
+	client := meta.Client
 	pubkey := d.Get("public_key").(string)
+	adopt_existing := d.Get("adopt_existing").(bool)
 
-	err := m.(*mullvadapi.Client).AddWireGuardKey(pubkey)
-	if err != nil {
-		return err
+	if adopt_existing {
+		if _, err := client.GetWireGuardKey(pubkey); err == nil {
+			log.Printf("[INFO] Adopting already registered key %s", pubkey)
+			d.SetId(pubkey)
+			return resourceMullvadWireguardRead(d, m)
+		} else if err != mullvadapi.ErrKeyNotFound {
+			return err
+		}
+	}
+
+	if err := meta.quota.create(quotaKeys, func() error { return client.AddWireGuardKey(pubkey) }); err != nil {
+		if !adopt_existing {
+			return err
+		}
+
+		// The key may have been registered despite the error, e.g. if the
+		// response was lost, or it was registered concurrently elsewhere.
+		if _, get_err := client.GetWireGuardKey(pubkey); get_err != nil {
+			return err
+		}
+
+		log.Printf("[INFO] Adopting key %s registered despite error: %s", pubkey, err)
 	}
 
 	d.SetId(pubkey)
@@ -60,7 +131,7 @@ func resourceMullvadWireguardCreate(d *schema.ResourceData, m interface{}) error
 }
 
 func resourceMullvadWireguardRead(d *schema.ResourceData, m interface{}) error {
-	key, err := m.(*mullvadapi.Client).GetWireGuardKey(d.Get("public_key").(string))
+	key, err := m.(*providerMeta).Client.GetWireGuardKey(d.Get("public_key").(string))
 	if err != nil {
 		if err == mullvadapi.ErrKeyNotFound {
 			d.SetId("")
@@ -75,9 +146,114 @@ func resourceMullvadWireguardRead(d *schema.ResourceData, m interface{}) error {
 	d.Set("ipv6_address", key.IpV6Address)
 	d.Set("ports", key.Ports)
 
+	if expiry, err := keyExpiry(d); err != nil {
+		return err
+	} else if expiry != nil {
+		d.Set("expires_at", expiry.Format(time.RFC3339))
+	} else {
+		d.Set("expires_at", "")
+	}
+
+	return nil
+}
+
+// The API has been seen to give only a date, rather than a timestamp.
+func parseKeyCreated(created string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, created); err == nil {
+		return t, nil
+	}
+
+	return time.Parse("2006-01-02", created)
+}
+
+func keyExpiry(d interface{ Get(string) interface{} }) (*time.Time, error) {
+	rotation_days := d.Get("rotation_days").(int)
+	if rotation_days <= 0 {
+		return nil, nil
+	}
+
+	created, err := parseKeyCreated(d.Get("created").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	expiry := created.AddDate(0, 0, rotation_days)
+	return &expiry, nil
+}
+
+func resourceMullvadWireguardCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" {
+		if !isCreate(d) {
+			return nil
+		}
+
+		client := m.(*providerMeta).Client
+		if client.IsLoggedIn() && d.Get("adopt_existing").(bool) {
+			if _, err := client.GetWireGuardKey(d.Get("public_key").(string)); err == nil {
+				// Already counted against the account's peers.
+				return nil
+			}
+		}
+
+		return checkQuota(m.(*providerMeta), quotaKeys)
+	}
+
+	if d.HasChange("rotation_days") {
+		if err := d.SetNewComputed("expires_at"); err != nil {
+			return err
+		}
+	}
+
+	if !d.HasChange("public_key") {
+		return nil
+	}
+
+	if !d.Get("rotate_in_place").(bool) {
+		return d.ForceNew("public_key")
+	}
+
+	old_public_key, _ := d.GetChange("public_key")
+	if _, err := m.(*providerMeta).Client.GetWireGuardKey(old_public_key.(string)); err != nil {
+		if err == mullvadapi.ErrKeyNotFound {
+			return errors.New(fmt.Sprintf(
+				"Key %s is no longer registered, so cannot be rotated in place, keeping its addresses and ports - set `rotate_in_place = false` to register the new key afresh",
+				old_public_key,
+			))
+		}
+
+		return err
+	}
+
+	// Re-registered, so these change with the key.
+	for _, k := range []string{"created", "expires_at"} {
+		if err := d.SetNewComputed(k); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+func resourceMullvadWireguardUpdate(d *schema.ResourceData, m interface{}) error {
+	if d.HasChange("public_key") {
+		old_public_key, new_public_key := d.GetChange("public_key")
+
+		_, err := m.(*providerMeta).Client.ReplaceWireGuardKey(old_public_key.(string), new_public_key.(string))
+		if err != nil {
+			var app_key *mullvadapi.AppKeyError
+			if errors.As(err, &app_key) {
+				return withAppKeyHint(err)
+			}
+
+			return errors.New(fmt.Sprintf("%s - set `rotate_in_place = false` to rotate by revoking and registering a new key instead", err))
+		}
+
+		d.SetId(new_public_key.(string))
+	}
+
+	return resourceMullvadWireguardRead(d, m)
+}
+
 func resourceMullvadWireguardDelete(d *schema.ResourceData, m interface{}) error {
-	return m.(*mullvadapi.Client).RevokeWireGuardKey(d.Get("public_key").(string))
+	return withAppKeyHint(m.(*providerMeta).Client.RevokeWireGuardKey(d.Get("public_key").(string)))
 }
@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"log"
+	"time"
+)
+
+func resourceMullvadWireguardKeypair() *schema.Resource {
+	return &schema.Resource{
+		Description: "Generates a WireGuard keypair, and replaces it once it's older than `rotation_days`. The key is only stored in the Terraform state; use its `public_key` in a `mullvad_wireguard` to register it, which then rotates in place.",
+
+		Create: resourceMullvadWireguardKeypairCreate,
+		Read:   resourceMullvadWireguardKeypairRead,
+		Update: resourceMullvadWireguardKeypairRead,
+		Delete: resourceMullvadWireguardKeypairDelete,
+
+		CustomizeDiff: resourceMullvadWireguardKeypairCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"created": {
+				Description: "Timestamp (RFC3339) at which the keypair was generated.",
+				Computed:    true,
+				Type:        schema.TypeString,
+			},
+			"expires_at": {
+				Description: "Timestamp (RFC3339) after which the keypair will be replaced, if `rotation_days` is set.",
+				Computed:    true,
+				Type:        schema.TypeString,
+			},
+			"private_key": {
+				Description: "The (secret) private key, base64-encoded as by `wg genkey`.",
+				Computed:    true,
+				Sensitive:   true,
+				Type:        schema.TypeString,
+			},
+			"public_key": {
+				Description: "The public key, base64-encoded as by `wg pubkey`.",
+				Computed:    true,
+				Type:        schema.TypeString,
+			},
+			"rotation_days": {
+				Description: "Number of days after generation after which to plan the keypair's replacement.",
+				Optional:    true,
+				Type:        schema.TypeInt,
+			},
+		},
+	}
+}
+
+// generateKeypair returns a private key clamped as by `wg genkey`, and its
+// public key.
+func generateKeypair() (string, string, error) {
+	private_key := make([]byte, 32)
+	if _, err := rand.Read(private_key); err != nil {
+		return "", "", err
+	}
+
+	private_key[0] &= 248
+	private_key[31] = (private_key[31] & 127) | 64
+
+	key, err := ecdh.X25519().NewPrivateKey(private_key)
+	if err != nil {
+		return "", "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(key.Bytes()), base64.StdEncoding.EncodeToString(key.PublicKey().Bytes()), nil
+}
+
+func resourceMullvadWireguardKeypairCreate(d *schema.ResourceData, m interface{}) error {
+	private_key, public_key, err := generateKeypair()
+	if err != nil {
+		return err
+	}
+
+	d.SetId(public_key)
+	d.Set("created", time.Now().UTC().Format(time.RFC3339))
+	d.Set("private_key", private_key)
+	d.Set("public_key", public_key)
+	return resourceMullvadWireguardKeypairRead(d, m)
+}
+
+func resourceMullvadWireguardKeypairRead(d *schema.ResourceData, m interface{}) error {
+	if expiry, err := keyExpiry(d); err != nil {
+		return err
+	} else if expiry != nil {
+		d.Set("expires_at", expiry.Format(time.RFC3339))
+	} else {
+		d.Set("expires_at", "")
+	}
+
+	return nil
+}
+
+func resourceMullvadWireguardKeypairDelete(d *schema.ResourceData, m interface{}) error {
+	// Only ever existed in the state.
+	return nil
+}
+
+func resourceMullvadWireguardKeypairCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	if d.HasChange("rotation_days") {
+		if err := d.SetNewComputed("expires_at"); err != nil {
+			return err
+		}
+	}
+
+	if expiry, err := keyExpiry(d); err != nil {
+		return err
+	} else if expiry != nil && time.Now().After(*expiry) {
+		log.Printf("[INFO] Keypair %s expired at %s, replacing", d.Id(), expiry.Format(time.RFC3339))
+		for _, k := range []string{"created", "expires_at", "private_key", "public_key"} {
+			if err := d.SetNewComputed(k); err != nil {
+				return err
+			}
+		}
+
+		return d.ForceNew("created")
+	}
+
+	return nil
+}
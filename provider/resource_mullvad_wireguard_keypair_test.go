@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"encoding/base64"
+	"testing"
+)
+
+func TestGenerateKeypair(t *testing.T) {
+	private_key, public_key, err := generateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	private_bytes, err := base64.StdEncoding.DecodeString(private_key)
+	if err != nil || len(private_bytes) != 32 {
+		t.Fatalf("Expected a base64-encoded 32-byte private key, got %d bytes (%v)", len(private_bytes), err)
+	}
+
+	if private_bytes[0]&7 != 0 || private_bytes[31]&128 != 0 || private_bytes[31]&64 == 0 {
+		t.Error("Expected the private key to be clamped")
+	}
+
+	key, err := ecdh.X25519().NewPrivateKey(private_bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if public_bytes, _ := base64.StdEncoding.DecodeString(public_key); !bytes.Equal(public_bytes, key.PublicKey().Bytes()) {
+		t.Error("Expected the public key to be that of the private key")
+	}
+
+	if other_private_key, _, _ := generateKeypair(); other_private_key == private_key {
+		t.Error("Expected a different key each time")
+	}
+}
@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"log"
+	"time"
+)
+
+func resourceMullvadWireguardPrune() *schema.Resource {
+	return &schema.Resource{
+		Description: "Revokes every WireGuard key registered on the account other than those to keep, optionally limited to old or non-app keys. Any keys to be pruned are revoked on each apply.",
+
+		Create: resourceMullvadWireguardPruneCreate,
+		Read:   resourceMullvadWireguardPruneRead,
+		Update: resourceMullvadWireguardPruneCreate,
+		Delete: resourceMullvadWireguardPruneDelete,
+
+		CustomizeDiff: resourceMullvadWireguardPruneCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"candidates": {
+				Description: "The public keys which match the criteria to be revoked, as of the last refresh (or apply).",
+				Computed:    true,
+				Type:        schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"dry_run": {
+				Description: "Whether to only list the `candidates`, without revoking them.",
+				Default:     false,
+				Optional:    true,
+				Type:        schema.TypeBool,
+			},
+			"exclude_app_registered": {
+				Description: "Whether to keep keys registered by the Mullvad app. (Otherwise, revoking them fails unless the provider is configured with `allow_revoke_app_keys`.)",
+				Default:     false,
+				Optional:    true,
+				Type:        schema.TypeBool,
+			},
+			"keep": {
+				Description: "The public keys to keep - including any managed by `mullvad_wireguard` resources.",
+				Required:    true,
+				Type:        schema.TypeSet,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"older_than_days": {
+				Description: "If set, only keys registered more than this many days ago are revoked.",
+				Optional:    true,
+				Type:        schema.TypeInt,
+			},
+			"revoked": {
+				Description: "The public keys revoked by the last apply.",
+				Computed:    true,
+				Type:        schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func pruneCandidates(d *schema.ResourceData, m interface{}) ([]string, error) {
+	key_list, err := m.(*providerMeta).Client.ListWireGuardKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	keep := d.Get("keep").(*schema.Set)
+	older_than_days := d.Get("older_than_days").(int)
+	exclude_app_registered := d.Get("exclude_app_registered").(bool)
+
+	candidates := make([]string, 0)
+	for _, key := range key_list.Keys {
+		if keep.Contains(key.KeyPair.PublicKey) {
+			continue
+		}
+
+		if exclude_app_registered && key.WasAppRegistered {
+			continue
+		}
+
+		if older_than_days > 0 {
+			created, err := parseKeyCreated(key.Created)
+			if err != nil {
+				return nil, err
+			}
+
+			if time.Since(created) <= time.Duration(older_than_days)*24*time.Hour {
+				continue
+			}
+		}
+
+		candidates = append(candidates, key.KeyPair.PublicKey)
+	}
+
+	return candidates, nil
+}
+
+func resourceMullvadWireguardPruneCreate(d *schema.ResourceData, m interface{}) error {
+	candidates, err := pruneCandidates(d, m)
+	if err != nil {
+		return err
+	}
+
+	revoked := make([]string, 0)
+	if !d.Get("dry_run").(bool) {
+		for _, public_key := range candidates {
+			log.Printf("[INFO] Pruning key %s", public_key)
+			if err := m.(*providerMeta).Client.RevokeWireGuardKey(public_key); err != nil {
+				d.Set("revoked", revoked)
+				return withAppKeyHint(err)
+			}
+
+			revoked = append(revoked, public_key)
+		}
+	}
+
+	d.SetId("wireguard_prune")
+	d.Set("revoked", revoked)
+	return resourceMullvadWireguardPruneRead(d, m)
+}
+
+func resourceMullvadWireguardPruneRead(d *schema.ResourceData, m interface{}) error {
+	candidates, err := pruneCandidates(d, m)
+	if err != nil {
+		return err
+	}
+
+	d.Set("candidates", candidates)
+	return nil
+}
+
+// Keys found to prune on refresh are drift, to be pruned by an update.
+func resourceMullvadWireguardPruneCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" || d.Get("dry_run").(bool) {
+		return nil
+	}
+
+	if len(d.Get("candidates").([]interface{})) > 0 {
+		if err := d.SetNewComputed("revoked"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func resourceMullvadWireguardPruneDelete(d *schema.ResourceData, m interface{}) error {
+	// Nothing to undo
+	return nil
+}